@@ -0,0 +1,382 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"time"
+)
+
+// arrayDesc is the descriptor of an ARRAY column read from RDB$FIELDS and RDB$FIELD_DIMENSIONS.
+type arrayDesc struct {
+	relation  string
+	field     string
+	blrType   int // RDB$FIELD_TYPE of the elements
+	scale     int
+	length    int // bytes of CHAR and VARCHAR elements
+	charsetId int
+	lower     []int
+	upper     []int
+}
+
+// sqltype of the elements by RDB$FIELD_TYPE
+var arrayElementSQLType = map[int]int{
+	7:  SQL_TYPE_SHORT,
+	8:  SQL_TYPE_LONG,
+	10: SQL_TYPE_FLOAT,
+	12: SQL_TYPE_DATE,
+	13: SQL_TYPE_TIME,
+	14: SQL_TYPE_TEXT,
+	16: SQL_TYPE_INT64,
+	23: SQL_TYPE_BOOLEAN,
+	27: SQL_TYPE_DOUBLE,
+	35: SQL_TYPE_TIMESTAMP,
+	37: SQL_TYPE_VARYING,
+}
+
+// elementLength returns the bytes of an element in the slice, as the length of a slice counts.
+func (d *arrayDesc) elementLength() int {
+	switch d.blrType {
+	case 7:
+		return 2
+	case 14:
+		return d.length
+	case 23:
+		return 1
+	case 37:
+		return d.length + 2
+	}
+	return xsqlvarTypeLength[arrayElementSQLType[d.blrType]]
+}
+
+// count returns the number of elements of the whole array.
+func (d *arrayDesc) count() int {
+	n := 1
+	for i := range d.lower {
+		n *= d.upper[i] - d.lower[i] + 1
+	}
+	return n
+}
+
+// xsqlvar returns a xSQLVAR to decode the elements as column values.
+func (d *arrayDesc) xsqlvar(p *wireProtocol) *xSQLVAR {
+	return &xSQLVAR{wp: p, sqltype: arrayElementSQLType[d.blrType], sqlscale: d.scale, sqlsubtype: d.charsetId, sqllen: d.length}
+}
+
+func sdlLiteral(sdl []byte, n int) []byte {
+	switch {
+	case n >= math.MinInt8 && n <= math.MaxInt8:
+		return append(sdl, isc_sdl_tiny_integer, byte(n))
+	case n >= math.MinInt16 && n <= math.MaxInt16:
+		return append(sdl, isc_sdl_short_integer, byte(n), byte(n>>8))
+	}
+	return append(sdl, isc_sdl_long_integer, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+}
+
+// sdl returns the slice description of the whole array.
+func (d *arrayDesc) sdl() []byte {
+	sdl := []byte{isc_sdl_version1, isc_sdl_struct, 1, byte(d.blrType)}
+	switch d.blrType {
+	case 7, 8, 16:
+		sdl = append(sdl, byte(d.scale))
+	case 14, 37:
+		sdl = append(sdl, byte(d.length), byte(d.length>>8))
+	}
+	sdl = append(sdl, isc_sdl_relation, byte(len(d.relation)))
+	sdl = append(sdl, d.relation...)
+	sdl = append(sdl, isc_sdl_field, byte(len(d.field)))
+	sdl = append(sdl, d.field...)
+	for i := range d.lower {
+		if d.lower[i] == 1 {
+			sdl = append(sdl, isc_sdl_do1, byte(i))
+		} else {
+			sdl = append(sdl, isc_sdl_do2, byte(i))
+			sdl = sdlLiteral(sdl, d.lower[i])
+		}
+		sdl = sdlLiteral(sdl, d.upper[i])
+	}
+	sdl = append(sdl, isc_sdl_element, 1, isc_sdl_scalar, 0, byte(len(d.lower)))
+	for i := range d.lower {
+		sdl = append(sdl, isc_sdl_variable, byte(i))
+	}
+	return append(sdl, isc_sdl_eoc)
+}
+
+// readElement receives an element of op_slice.
+func (d *arrayDesc) readElement(p *wireProtocol) (v interface{}, err error) {
+	var raw []byte
+	switch d.blrType {
+	case 14:
+		raw, err = p.recvPacketsAlignment(d.length)
+	case 23:
+		raw, err = p.recvPacketsAlignment(1)
+	case 37:
+		if raw, err = p.recvPackets(4); err == nil {
+			raw, err = p.recvPacketsAlignment(int(bytes_to_bint32(raw)))
+		}
+	case 7:
+		raw, err = p.recvPackets(4)
+	default:
+		raw, err = p.recvPackets(d.elementLength())
+	}
+	if err != nil {
+		return
+	}
+	return d.xsqlvar(p).value(raw)
+}
+
+// elementBytes encodes an element for op_put_slice.
+func (d *arrayDesc) elementBytes(v interface{}) ([]byte, error) {
+	switch d.blrType {
+	case 7, 8, 16:
+		n, ok := scaledInteger(v, d.scale)
+		switch {
+		case !ok || !n.IsInt64():
+		case d.blrType == 7 && n.Int64() >= math.MinInt16 && n.Int64() <= math.MaxInt16,
+			d.blrType == 8 && n.Int64() >= math.MinInt32 && n.Int64() <= math.MaxInt32:
+			return bint32_to_bytes(int32(n.Int64())), nil
+		case d.blrType == 16:
+			return bint64_to_bytes(n.Int64()), nil
+		}
+	case 10, 27:
+		rv := reflect.ValueOf(v)
+		var f float64
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f = rv.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(rv.Int())
+		default:
+			return nil, errors.New(fmt.Sprintf("Cannot convert %T to an element of %s", v, d.field))
+		}
+		if d.blrType == 10 {
+			return bint32_to_bytes(int32(math.Float32bits(float32(f)))), nil
+		}
+		return bint64_to_bytes(int64(math.Float64bits(f))), nil
+	case 12, 13, 35:
+		t, ok := v.(time.Time)
+		if !ok {
+			break
+		}
+		switch d.blrType {
+		case 12:
+			_, b := _dateToBlr(t)
+			return b, nil
+		case 13:
+			_, b := _timeToBlr(t)
+			return b, nil
+		}
+		_, b := _timestampToBlr(t)
+		return b, nil
+	case 23:
+		if b, ok := v.(bool); ok && b {
+			return []byte{1, 0, 0, 0}, nil
+		} else if ok {
+			return []byte{0, 0, 0, 0}, nil
+		}
+	case 14, 37:
+		var b []byte
+		switch s := v.(type) {
+		case string:
+			b = str_to_bytes(s)
+		case []byte:
+			b = s
+		default:
+			return nil, errors.New(fmt.Sprintf("Cannot convert %T to an element of %s", v, d.field))
+		}
+		if len(b) > d.length {
+			return nil, errors.New(fmt.Sprintf("Element of %s is longer than %d bytes", d.field, d.length))
+		}
+		if d.blrType == 37 {
+			return append(bint32_to_bytes(int32(len(b))), xdrPadded(b)...), nil
+		}
+		pad := byte(' ')
+		if d.charsetId == 1 { // OCTETS
+			pad = 0
+		}
+		return xdrPadded(append(b, bytes.Repeat([]byte{pad}, d.length-len(b))...)), nil
+	}
+	return nil, errors.New(fmt.Sprintf("Cannot convert %v to an element of %s", v, d.field))
+}
+
+// flattenArray returns the elements of nested slices or arrays v in row major order,
+// checking that the lengths match the dimensions.
+func (d *arrayDesc) flattenArray(v reflect.Value, dim int, elements []interface{}) ([]interface{}, error) {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, errors.New(fmt.Sprintf("%s has %d dimensions", d.field, len(d.lower)))
+	}
+	if n := d.upper[dim] - d.lower[dim] + 1; v.Len() != n {
+		return nil, errors.New(fmt.Sprintf("Dimension %d of %s has %d elements, not %d", dim+1, d.field, n, v.Len()))
+	}
+	var err error
+	for i := 0; i < v.Len(); i++ {
+		if dim+1 < len(d.lower) {
+			if elements, err = d.flattenArray(v.Index(i), dim+1, elements); err != nil {
+				return nil, err
+			}
+		} else {
+			elements = append(elements, v.Index(i).Interface())
+		}
+	}
+	return elements, nil
+}
+
+// nestArray makes nested slices of the dimensions from elements in row major order.
+func (d *arrayDesc) nestArray(elements []interface{}, dim int, elemType reflect.Type) reflect.Value {
+	t := elemType
+	for i := dim; i < len(d.lower); i++ {
+		t = reflect.SliceOf(t)
+	}
+	n := d.upper[dim] - d.lower[dim] + 1
+	v := reflect.MakeSlice(t, n, n)
+	size := len(elements) / n
+	for i := 0; i < n; i++ {
+		if dim+1 < len(d.lower) {
+			v.Index(i).Set(d.nestArray(elements[i*size:(i+1)*size], dim+1, elemType))
+		} else if elements[i] != nil {
+			v.Index(i).Set(reflect.ValueOf(elements[i]))
+		}
+	}
+	return v
+}
+
+// getSlice reads the whole array of sliceId as nested slices, e.g. [][]int32 for INTEGER[2,3].
+func (p *wireProtocol) getSlice(sliceId []byte, transHandle int32, d *arrayDesc) (interface{}, error) {
+	suspendBuf := p.suspendBuffer()
+	defer p.resumeBuffer(suspendBuf)
+	p.opGetSlice(transHandle, sliceId, int32(d.count()*d.elementLength()), d.sdl())
+	sliceLength, err := p.opSliceResponse()
+	if err != nil {
+		return nil, err
+	}
+	n := int(sliceLength) / d.elementLength()
+	if n != d.count() {
+		return nil, errors.New(fmt.Sprintf("Slice of %s has %d elements, not %d", d.field, n, d.count()))
+	}
+	elements := make([]interface{}, n)
+	for i := range elements {
+		if elements[i], err = d.readElement(p); err != nil {
+			return nil, err
+		}
+	}
+	return d.nestArray(elements, 0, reflect.TypeOf(elements[0])).Interface(), nil
+}
+
+// putSlice writes v as a new array and returns its id.
+func (p *wireProtocol) putSlice(v interface{}, transHandle int32, d *arrayDesc) ([]byte, error) {
+	elements, err := d.flattenArray(reflect.ValueOf(v), 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	var slice []byte
+	for _, e := range elements {
+		b, err := d.elementBytes(e)
+		if err != nil {
+			return nil, err
+		}
+		slice = append(slice, b...)
+	}
+	suspendBuf := p.suspendBuffer()
+	defer p.resumeBuffer(suspendBuf)
+	p.opPutSlice(transHandle, int32(len(elements)*d.elementLength()), d.sdl(), slice)
+	_, sliceId, _, err := p.opResponse()
+	return sliceId, err
+}
+
+// isArrayValue reports whether v is given to an ARRAY parameter.
+func isArrayValue(v interface{}) bool {
+	t := reflect.TypeOf(v)
+	if t == nil || (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) {
+		return false
+	}
+	return t.Elem().Kind() != reflect.Uint8
+}
+
+// arrayId is an ARRAY parameter written by op_put_slice.
+type arrayId []byte
+
+// arrayDesc returns the descriptor of the ARRAY column field of relation.
+func (fc *firebirdsqlConn) arrayDesc(relation string, field string) (d *arrayDesc, err error) {
+	key := relation + "." + field
+	if d = fc.arrayDescs[key]; d != nil {
+		return
+	}
+	if relation == "" || field == "" {
+		return nil, errors.New("ARRAY of an expression is not supported")
+	}
+	rows, err := fc.query(context.Background(), `
+		SELECT f.rdb$field_type, COALESCE(f.rdb$field_scale, 0), f.rdb$field_length,
+		COALESCE(f.rdb$character_set_id, 0), d.rdb$lower_bound, d.rdb$upper_bound
+		FROM rdb$relation_fields rf
+		JOIN rdb$fields f ON f.rdb$field_name = rf.rdb$field_source
+		JOIN rdb$field_dimensions d ON d.rdb$field_name = f.rdb$field_name
+		WHERE rf.rdb$relation_name = ? AND rf.rdb$field_name = ?
+		ORDER BY d.rdb$dimension`, []driver.NamedValue{{Ordinal: 1, Value: relation}, {Ordinal: 2, Value: field}})
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	d = &arrayDesc{relation: relation, field: field}
+	row := make([]driver.Value, 6)
+	for {
+		if err = rows.Next(row); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		d.blrType, d.scale, d.length, d.charsetId = intValue(row[0]), intValue(row[1]), intValue(row[2]), intValue(row[3])
+		d.lower = append(d.lower, intValue(row[4]))
+		d.upper = append(d.upper, intValue(row[5]))
+	}
+	if len(d.lower) == 0 {
+		return nil, errors.New(fmt.Sprintf("%s.%s is not an ARRAY column", relation, field))
+	}
+	if _, ok := arrayElementSQLType[d.blrType]; !ok {
+		return nil, errors.New(fmt.Sprintf("ARRAY of %s.%s has an unsupported element type %d", relation, field, d.blrType))
+	}
+	if fc.arrayDescs == nil {
+		fc.arrayDescs = make(map[string]*arrayDesc)
+	}
+	fc.arrayDescs[key] = d
+	return d, nil
+}
+
+func intValue(v driver.Value) int {
+	switch n := v.(type) {
+	case int16:
+		return int(n)
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	}
+	return 0
+}
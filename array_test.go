@@ -0,0 +1,124 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestArraySdl(t *testing.T) {
+	d := &arrayDesc{relation: "T", field: "A", blrType: 8, lower: []int{1, 0}, upper: []int{2, 300}}
+	expected := []byte{
+		isc_sdl_version1, isc_sdl_struct, 1, 8, 0,
+		isc_sdl_relation, 1, 'T', isc_sdl_field, 1, 'A',
+		isc_sdl_do1, 0, isc_sdl_tiny_integer, 2,
+		isc_sdl_do2, 1, isc_sdl_tiny_integer, 0, isc_sdl_short_integer, 0x2c, 0x01,
+		isc_sdl_element, 1, isc_sdl_scalar, 0, 2, isc_sdl_variable, 0, isc_sdl_variable, 1,
+		isc_sdl_eoc,
+	}
+	if sdl := d.sdl(); !bytes.Equal(sdl, expected) {
+		t.Errorf("Incorrect sdl:%v", sdl)
+	}
+	if d.count() != 602 || d.elementLength() != 4 {
+		t.Errorf("Incorrect size:%v %v", d.count(), d.elementLength())
+	}
+
+	d = &arrayDesc{relation: "T", field: "S", blrType: 37, length: 5, lower: []int{1}, upper: []int{1}}
+	if sdl := d.sdl(); !bytes.Equal(sdl[:6], []byte{isc_sdl_version1, isc_sdl_struct, 1, 37, 5, 0}) || d.elementLength() != 7 {
+		t.Errorf("Incorrect varchar sdl:%v", sdl)
+	}
+}
+
+func TestArrayElements(t *testing.T) {
+	d := &arrayDesc{field: "A", blrType: 7, lower: []int{1, 1}, upper: []int{2, 2}}
+	elements, err := d.flattenArray(reflect.ValueOf([][]int{{1, 2}, {3, 4}}), 0, nil)
+	if err != nil || !reflect.DeepEqual(elements, []interface{}{1, 2, 3, 4}) {
+		t.Fatalf("Incorrect elements:%v %v", elements, err)
+	}
+	if _, err = d.flattenArray(reflect.ValueOf([][]int{{1, 2}}), 0, nil); err == nil {
+		t.Errorf("Dimension mismatch is not detected")
+	}
+	if _, err = d.flattenArray(reflect.ValueOf([]int{1, 2}), 0, nil); err == nil {
+		t.Errorf("Dimension count mismatch is not detected")
+	}
+	if b, err := d.elementBytes(int64(-2)); err != nil || !bytes.Equal(b, []byte{0xff, 0xff, 0xff, 0xfe}) {
+		t.Errorf("Incorrect smallint:%v %v", b, err)
+	}
+	if _, err = d.elementBytes(int64(40000)); err == nil {
+		t.Errorf("Overflow is not detected")
+	}
+
+	nested := d.nestArray([]interface{}{int16(1), int16(2), int16(3), int16(4)}, 0, reflect.TypeOf(int16(0)))
+	if !reflect.DeepEqual(nested.Interface(), [][]int16{{1, 2}, {3, 4}}) {
+		t.Errorf("Incorrect nested array:%v", nested.Interface())
+	}
+
+	d = &arrayDesc{field: "S", blrType: 37, length: 4, lower: []int{1}, upper: []int{1}}
+	if b, err := d.elementBytes("abc"); err != nil || !bytes.Equal(b, []byte{0, 0, 0, 3, 'a', 'b', 'c', 0}) {
+		t.Errorf("Incorrect varchar:%v %v", b, err)
+	}
+	if _, err = d.elementBytes("abcde"); err == nil {
+		t.Errorf("Long string is not detected")
+	}
+	d.blrType = 14
+	if b, err := d.elementBytes("ab"); err != nil || !bytes.Equal(b, []byte{'a', 'b', ' ', ' '}) {
+		t.Errorf("Incorrect char:%v %v", b, err)
+	}
+
+	if !isArrayValue([]int32{1}) || !isArrayValue([2]string{}) || isArrayValue([]byte{1}) || isArrayValue("a") || isArrayValue(nil) {
+		t.Errorf("Incorrect isArrayValue")
+	}
+}
+
+func TestGetSlice(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	conn, err := newWireChannel(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &wireProtocol{buf: make([]byte, 0, BUFFER_LEN), conn: conn}
+
+	go func() {
+		b := make([]byte, BUFFER_LEN)
+		server.Read(b)
+		server.Write([]byte{
+			0, 0, 0, op_slice, 0, 0, 0, 8, 0, 0, 0, 8,
+			0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 3, 0xff, 0xff, 0xff, 0xfc,
+		})
+	}()
+
+	d := &arrayDesc{relation: "T", field: "A", blrType: 7, lower: []int{1, 0}, upper: []int{2, 1}}
+	v, err := p.getSlice(make([]byte, 8), 1, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, [][]int16{{1, 2}, {3, -4}}) {
+		t.Errorf("Incorrect slice:%v", v)
+	}
+}
@@ -0,0 +1,415 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+const defaultBatchChunkSize = 1000
+
+// Values of BatchCompletionState.UpdateCounts other than the number of records.
+const (
+	BatchExecuteFailed = -1
+	BatchSuccessNoInfo = -2
+)
+
+// BatchOptions are the options of NewBatch.
+type BatchOptions struct {
+	ChunkSize      int  // rows sent to the server at a time (1000 by default)
+	MultiError     bool // continue after a failed row
+	RecordCounts   bool // report the update count of every row
+	BufferSize     int  // server side buffer size in bytes (server default if 0)
+	DetailedErrors int  // number of errors reported with the status (server default if 0)
+}
+
+// BatchError is an error of a row in a batch.
+type BatchError struct {
+	Record int   // index of the row from 0
+	Err    error // nil if the server does not report the detail
+}
+
+// BatchCompletionState is the result of Batch.Execute.
+type BatchCompletionState struct {
+	RecordCount  int
+	UpdateCounts []int // if BatchOptions.RecordCounts
+	Errors       []BatchError
+}
+
+// Batch executes a statement for many rows with the Firebird 4.0 batch API.
+// Rows are sent in chunks without waiting for the execution of each row.
+type Batch struct {
+	conn      *sql.Conn
+	stmt      *firebirdsqlStmt
+	chunkSize int
+	messages  []byte
+	count     int
+
+	blobAlign    int // alignment of the blob headers in the blob stream
+	blobCount    int32
+	blobStream   []byte // blobs of the rows not sent yet
+	streamLength int    // bytes of the blob stream on the server
+	streamSent   int
+}
+
+// NewBatch prepares query for a batch on conn (Firebird 4.0+).
+func NewBatch(ctx context.Context, conn *sql.Conn, query string, opts *BatchOptions) (batch *Batch, err error) {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+	batch = &Batch{conn: conn, chunkSize: opts.ChunkSize}
+	if batch.chunkSize <= 0 {
+		batch.chunkSize = defaultBatchChunkSize
+	}
+
+	err = rawConn(conn, func(fc *firebirdsqlConn) error {
+		if fc.wp.protocolVersion < PROTOCOL_VERSION16 {
+			return errors.New("Batch requires Firebird 4.0 or later")
+		}
+		stmt, err := newFirebirdsqlStmt(fc, query)
+		if err != nil {
+			return err
+		}
+		blr, msgLength, hasBlob, err := batchFormat(stmt.bindXsqlda)
+		if err == nil {
+			fc.wp.deferPackets()
+			fc.wp.opBatchCreate(stmt.stmtHandle, blr, int32(msgLength), batchParams(opts, hasBlob))
+			if hasBlob {
+				fc.wp.opInfoSql(stmt.stmtHandle, []byte{isc_info_sql_stmt_blob_align}, int32(BUFFER_LEN))
+			}
+			_, _, _, err = fc.wp.opResponse()
+			if hasBlob {
+				_, _, buf, infoErr := fc.wp.opResponse()
+				if err == nil {
+					err = infoErr
+				}
+				if err == nil {
+					batch.blobAlign, err = parseBlobAlign(buf)
+				}
+			}
+		}
+		if err != nil {
+			stmt.Close()
+			return err
+		}
+		batch.stmt = stmt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Add appends a row of parameters to the batch.
+func (batch *Batch) Add(args ...interface{}) error {
+	namedargs, err := interfacesToNamedValues(args)
+	if err != nil {
+		return err
+	}
+	return rawConn(batch.conn, func(fc *firebirdsqlConn) error {
+		params, err := bindNamedValues(batch.stmt.paramNames, namedargs)
+		if err != nil {
+			return err
+		}
+		if err = fc.tx.beginIfNeeded(); err != nil {
+			return err
+		}
+		msg, err := batch.message(params)
+		if err != nil {
+			return err
+		}
+		batch.messages = append(batch.messages, msg...)
+		batch.count++
+		if batch.count >= batch.chunkSize {
+			return batch.flush()
+		}
+		return nil
+	})
+}
+
+// Flush sends the rows added so far to the server.
+func (batch *Batch) Flush() error {
+	return rawConn(batch.conn, func(fc *firebirdsqlConn) error {
+		return batch.flush()
+	})
+}
+
+// Execute executes all rows sent to the server in the current transaction.
+// The batch is empty after Execute and can be used again.
+func (batch *Batch) Execute() (cs *BatchCompletionState, err error) {
+	err = rawConn(batch.conn, func(fc *firebirdsqlConn) error {
+		if err := batch.flush(); err != nil {
+			return err
+		}
+		if err := fc.tx.beginIfNeeded(); err != nil {
+			return err
+		}
+		fc.wp.opBatchExec(batch.stmt.stmtHandle, fc.tx.transHandle)
+		cs, err = fc.wp.opBatchCsResponse()
+		// the server clears the blob stream
+		batch.streamLength = 0
+		batch.streamSent = 0
+		if err == nil && fc.isAutocommit && fc.tx.isAutocommit {
+			err = fc.tx.Commit()
+		}
+		return err
+	})
+	return
+}
+
+// Close releases the batch and the statement. Rows not executed are discarded.
+func (batch *Batch) Close() error {
+	return rawConn(batch.conn, func(fc *firebirdsqlConn) error {
+		batch.messages = nil
+		batch.count = 0
+		batch.blobStream = nil
+		fc.wp.opBatchRls(batch.stmt.stmtHandle)
+		_, _, _, err := fc.wp.opResponse()
+		if e := batch.stmt.Close(); err == nil {
+			err = e
+		}
+		return err
+	})
+}
+
+// flush sends the blobs and the messages of the rows added in a write.
+func (batch *Batch) flush() (err error) {
+	if batch.count == 0 && len(batch.blobStream) == 0 {
+		return
+	}
+	wp := batch.stmt.wp
+	wp.deferPackets()
+	responses := 0
+	if len(batch.blobStream) > 0 {
+		wp.opBatchBlobStream(batch.stmt.stmtHandle, int32(batch.streamLength-batch.streamSent), batch.blobStream)
+		responses++
+	}
+	if batch.count > 0 {
+		wp.opBatchMsg(batch.stmt.stmtHandle, int32(batch.count), batch.messages)
+		responses++
+	}
+	for ; responses > 0; responses-- {
+		if _, _, _, e := wp.opResponse(); err == nil {
+			err = e
+		}
+	}
+	batch.messages = batch.messages[:0]
+	batch.count = 0
+	batch.blobStream = batch.blobStream[:0]
+	batch.streamSent = batch.streamLength
+	return
+}
+
+// addBlob appends a blob to the blob stream. A blob is a header of the batch blob id,
+// the size and the BPB size aligned by blobAlign on the server, followed by the data.
+func (batch *Batch) addBlob(batchBlobId []byte, data []byte) {
+	batch.streamLength = (batch.streamLength+batch.blobAlign-1)/batch.blobAlign*batch.blobAlign + 16 + len(data)
+	batch.blobStream = append(batch.blobStream, batchBlobId...)
+	batch.blobStream = append(batch.blobStream, bint32_to_bytes(int32(len(data)))...)
+	batch.blobStream = append(batch.blobStream, bint32_to_bytes(0)...) // no BPB
+	batch.blobStream = append(batch.blobStream, xdrPadded(data)...)
+}
+
+// parseBlobAlign parses the reply of isc_info_sql_stmt_blob_align.
+func parseBlobAlign(buf []byte) (int, error) {
+	items, err := parseInfoItems(buf)
+	if err != nil {
+		return 0, err
+	}
+	align := int(bytes_to_portable_int(items[isc_info_sql_stmt_blob_align]))
+	if align <= 0 {
+		return 0, errors.New("Blob alignment of batch is not reported by the server")
+	}
+	return align, nil
+}
+
+// message encodes a row in the format given by batchFormat.
+func (batch *Batch) message(params []driver.Value) ([]byte, error) {
+	xsqlda := batch.stmt.bindXsqlda
+	if len(params) != len(xsqlda) {
+		return nil, errors.New(fmt.Sprintf("Expected %d arguments, got %d", len(xsqlda), len(params)))
+	}
+	msg := nullBitmap(params)
+	for i, param := range params {
+		if param == nil {
+			continue
+		}
+		v, err := batch.value(&xsqlda[i], param)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Parameter %d: %v", i+1, err))
+		}
+		msg = append(msg, v...)
+	}
+	return msg, nil
+}
+
+func (batch *Batch) value(x *xSQLVAR, param driver.Value) ([]byte, error) {
+	wp := batch.stmt.wp
+	transHandle := batch.stmt.tx.transHandle
+	switch x.sqltype {
+	case SQL_TYPE_BLOB:
+		// blobs are sent in the blob stream and referred by the ids given in the batch
+		batch.blobCount++
+		batchBlobId := bytes.Join([][]byte{bint32_to_bytes(0), bint32_to_bytes(batch.blobCount)}, nil)
+		batch.addBlob(batchBlobId, bytesValue(param))
+		return batchBlobId, nil
+	case SQL_TYPE_TEXT, SQL_TYPE_VARYING, SQL_TYPE_DEC_FIXED, SQL_TYPE_DEC64, SQL_TYPE_DEC128:
+		b := bytesValue(param)
+		if len(b) > batchStringLength(x) {
+			return nil, errors.New(fmt.Sprintf("Value is too long for %s", x.typename()))
+		}
+		_, v := _bytesToBlr(b)
+		return append(bint32_to_bytes(int32(len(b))), v...), nil
+	}
+	blr, v, err := wp.paramToBlr(transHandle, x, param)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(blr, batchFieldBlr(x)) {
+		return nil, errors.New(fmt.Sprintf("Cannot convert %T to %s", param, x.typename()))
+	}
+	return v, nil
+}
+
+func bytesValue(param driver.Value) []byte {
+	switch t := param.(type) {
+	case []byte:
+		return t
+	case string:
+		return str_to_bytes(t)
+	}
+	return str_to_bytes(fmt.Sprintf("%v", param))
+}
+
+// batchStringLength is the length of the varying type sent for x.
+// Decimal types are sent as strings and converted by the server.
+func batchStringLength(x *xSQLVAR) int {
+	switch x.sqltype {
+	case SQL_TYPE_TEXT, SQL_TYPE_VARYING:
+		return x.sqllen
+	}
+	return 64
+}
+
+// batchFieldBlr returns the BLR of a batch message field for x,
+// or nil if the type is not supported in batches.
+func batchFieldBlr(x *xSQLVAR) []byte {
+	switch x.sqltype {
+	case SQL_TYPE_SHORT:
+		return []byte{7, byte(x.sqlscale)}
+	case SQL_TYPE_LONG:
+		return []byte{8, byte(x.sqlscale)}
+	case SQL_TYPE_INT64:
+		return []byte{16, byte(x.sqlscale)}
+	case SQL_TYPE_FLOAT, SQL_TYPE_DOUBLE:
+		return []byte{27}
+	case SQL_TYPE_DATE:
+		return []byte{12}
+	case SQL_TYPE_TIME:
+		return []byte{13}
+	case SQL_TYPE_TIMESTAMP:
+		return []byte{35}
+	case SQL_TYPE_BOOLEAN:
+		return []byte{23}
+	case SQL_TYPE_BLOB:
+		return []byte{9, 0}
+	case SQL_TYPE_TEXT, SQL_TYPE_VARYING, SQL_TYPE_DEC_FIXED, SQL_TYPE_DEC64, SQL_TYPE_DEC128:
+		ln := batchStringLength(x)
+		return []byte{37, byte(ln & 255), byte(ln >> 8)}
+	}
+	return nil
+}
+
+// batchFormat returns the message BLR of the parameters and the message length
+// the server computes from it.
+func batchFormat(xsqlda []xSQLVAR) (blr []byte, msgLength int, hasBlob bool, err error) {
+	if len(xsqlda) == 0 {
+		err = errors.New("Batch statement has no parameters")
+		return
+	}
+	ln := len(xsqlda) * 2
+	blr = []byte{5, 2, 4, 0, byte(ln & 255), byte(ln >> 8)}
+	for i := range xsqlda {
+		x := &xsqlda[i]
+		fieldBlr := batchFieldBlr(x)
+		if fieldBlr == nil {
+			err = errors.New(fmt.Sprintf("%s is not supported in batch", x.typename()))
+			return
+		}
+		blr = append(blr, fieldBlr...)
+		blr = append(blr, 7, 0) // null indicator
+
+		var length, alignment int
+		switch fieldBlr[0] {
+		case 7:
+			length, alignment = 2, 2
+		case 8, 12, 13:
+			length, alignment = 4, 4
+		case 16, 27:
+			length, alignment = 8, 8
+		case 35, 9:
+			length, alignment = 8, 4
+		case 23:
+			length, alignment = 1, 1
+		case 37:
+			length, alignment = batchStringLength(x)+2, 2
+		}
+		msgLength = (msgLength+alignment-1)/alignment*alignment + length
+		msgLength = (msgLength+1)/2*2 + 2 // null indicator
+		hasBlob = hasBlob || x.sqltype == SQL_TYPE_BLOB
+	}
+	blr = append(blr, 255, 76) // [blr_end, blr_eoc]
+	return
+}
+
+// batchParams returns the batch parameters block.
+func batchParams(opts *BatchOptions, hasBlob bool) []byte {
+	bpb := []byte{batch_version1}
+	add := func(tag byte, v int) {
+		bpb = append(bpb, tag, 4, 0, 0, 0)
+		bpb = append(bpb, int32_to_bytes(int32(v))...)
+	}
+	if opts.MultiError {
+		add(batch_tag_multierror, 1)
+	}
+	if opts.RecordCounts {
+		add(batch_tag_record_counts, 1)
+	}
+	if opts.BufferSize > 0 {
+		add(batch_tag_buffer_bytes, opts.BufferSize)
+	}
+	if opts.DetailedErrors > 0 {
+		add(batch_tag_detailed_errors, opts.DetailedErrors)
+	}
+	if hasBlob {
+		add(batch_tag_blob_policy, batch_blob_stream)
+	}
+	return bpb
+}
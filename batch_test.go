@@ -0,0 +1,156 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBatchFormat(t *testing.T) {
+	xsqlda := []xSQLVAR{
+		{sqltype: SQL_TYPE_LONG},
+		{sqltype: SQL_TYPE_VARYING, sqllen: 10},
+		{sqltype: SQL_TYPE_INT64, sqlscale: -2},
+		{sqltype: SQL_TYPE_BLOB},
+	}
+	blr, msgLength, hasBlob, err := batchFormat(xsqlda)
+	if err != nil {
+		t.Fatalf("Error batchFormat: %v", err)
+	}
+	expected := []byte{5, 2, 4, 0, 8, 0, 8, 0, 7, 0, 37, 10, 0, 7, 0, 16, 254, 7, 0, 9, 0, 7, 0, 255, 76}
+	if !bytes.Equal(blr, expected) {
+		t.Errorf("Incorrect blr: %v", blr)
+	}
+	// long(0-4) null(4-6) varying(6-18) null(18-20) int64(24-32) null(32-34) quad(36-44) null(44-46)
+	if msgLength != 46 {
+		t.Errorf("Incorrect message length: %v", msgLength)
+	}
+	if !hasBlob {
+		t.Errorf("Blob is not detected")
+	}
+
+	if _, _, _, err = batchFormat([]xSQLVAR{{sqltype: SQL_TYPE_TIME_TZ}}); err == nil {
+		t.Errorf("Unsupported type is not detected")
+	}
+
+	bpb := batchParams(&BatchOptions{MultiError: true}, true)
+	if !bytes.Equal(bpb, []byte{1, 1, 4, 0, 0, 0, 1, 0, 0, 0, 4, 4, 0, 0, 0, 3, 0, 0, 0}) {
+		t.Errorf("Incorrect batch parameters: %v", bpb)
+	}
+}
+
+func TestBatchBlobStream(t *testing.T) {
+	batch := &Batch{blobAlign: 8}
+	batch.addBlob([]byte{0, 0, 0, 0, 0, 0, 0, 1}, []byte("abc"))
+	batch.addBlob([]byte{0, 0, 0, 0, 0, 0, 0, 2}, []byte("de"))
+	expected := []byte{
+		0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 3, 0, 0, 0, 0, 'a', 'b', 'c', 0,
+		0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 2, 0, 0, 0, 0, 'd', 'e', 0, 0,
+	}
+	if !bytes.Equal(batch.blobStream, expected) {
+		t.Errorf("Incorrect blob stream: %v", batch.blobStream)
+	}
+	// header(0-16) abc(16-19) align(19-24) header(24-40) de(40-42)
+	if batch.streamLength != 42 {
+		t.Errorf("Incorrect stream length: %v", batch.streamLength)
+	}
+
+	align, err := parseBlobAlign([]byte{isc_info_sql_stmt_blob_align, 4, 0, 8, 0, 0, 0, isc_info_end})
+	if err != nil || align != 8 {
+		t.Errorf("Incorrect blob alignment: %v %v", align, err)
+	}
+	if _, err = parseBlobAlign([]byte{isc_info_end}); err == nil {
+		t.Errorf("Missing blob alignment is not detected")
+	}
+}
+
+func TestBatch(t *testing.T) {
+	temppath := TempFileName("test_batch_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_batch (id INTEGER NOT NULL PRIMARY KEY, name VARCHAR(10), amount NUMERIC(10, 2), memo BLOB SUB_TYPE TEXT)")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	c, err := conn.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Error Conn(): %v", err)
+	}
+	defer c.Close()
+
+	batch, err := NewBatch(ctx, c, "INSERT INTO test_batch (id, name, amount, memo) VALUES (?, ?, ?, ?)",
+		&BatchOptions{ChunkSize: 7, MultiError: true, RecordCounts: true})
+	if err != nil && strings.Contains(err.Error(), "Firebird 4.0") {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("Error NewBatch: %v", err)
+	}
+	defer batch.Close()
+
+	for i := 1; i <= 100; i++ {
+		if err = batch.Add(i, "name", 1.25, strings.Repeat("memo", i)); err != nil {
+			t.Fatalf("Error Add: %v", err)
+		}
+	}
+	batch.Add(1, nil, nil, nil) // duplicated
+	if err = batch.Add(102, "too long name", nil, nil); err == nil {
+		t.Fatalf("Too long value is not detected")
+	}
+
+	cs, err := batch.Execute()
+	if err != nil {
+		t.Fatalf("Error Execute: %v", err)
+	}
+	if cs.RecordCount != 101 || len(cs.UpdateCounts) != 101 || cs.UpdateCounts[0] != 1 || cs.UpdateCounts[100] != BatchExecuteFailed {
+		t.Fatalf("Incorrect completion state: %v %v", cs.RecordCount, cs.UpdateCounts)
+	}
+	if len(cs.Errors) != 1 || cs.Errors[0].Record != 100 || !hasGDSCode(cs.Errors[0].Err, 335544665) {
+		t.Fatalf("Incorrect errors: %v", cs.Errors)
+	}
+
+	var n int
+	var sum float64
+	var memo string
+	conn.QueryRow("SELECT count(*), sum(amount) FROM test_batch").Scan(&n, &sum)
+	conn.QueryRow("SELECT memo FROM test_batch WHERE id = 3").Scan(&memo)
+	if n != 100 || sum != 125 || memo != "memomemomemo" {
+		t.Fatalf("Incorrect result: %v %v %v", n, sum, memo)
+	}
+}
@@ -0,0 +1,187 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+)
+
+// ColumnType describes a column of a query result as reported by the server.
+type ColumnType struct {
+	Name             string // alias, as returned by Columns()
+	Field            string // original field name, empty for expressions
+	Relation         string // table, view or procedure the field belongs to
+	Owner            string // owner of the relation
+	DatabaseTypeName string
+	SQLType          int // SQL_TYPE_* without the nullable flag
+	SubType          int
+	CharacterSetID   int // character set of CHAR, VARCHAR and text BLOB
+	Scale            int
+	Length           int // bytes of CHAR and VARCHAR
+	Precision        int // digits of NUMERIC, DECIMAL and DECFLOAT, 0 for other types
+	Nullable         bool
+}
+
+// defaultPrecision is the precision of scaled numbers by their storage type.
+var defaultPrecision = map[int]int{
+	SQL_TYPE_SHORT:     4,
+	SQL_TYPE_LONG:      9,
+	SQL_TYPE_INT64:     18,
+	SQL_TYPE_DEC_FIXED: 34,
+	SQL_TYPE_DEC64:     16,
+	SQL_TYPE_DEC128:    34,
+}
+
+func newColumnType(x *xSQLVAR, name string) ColumnType {
+	ct := ColumnType{
+		Name:             name,
+		Field:            x.fieldname,
+		Relation:         x.relname,
+		Owner:            x.ownname,
+		DatabaseTypeName: x.typename(),
+		SQLType:          x.sqltype,
+		SubType:          x.sqlsubtype,
+		Scale:            x.sqlscale,
+		Length:           x.sqllen,
+		Nullable:         x.null_ok,
+	}
+	switch x.sqltype {
+	case SQL_TYPE_TEXT, SQL_TYPE_VARYING:
+		ct.CharacterSetID = x.sqlsubtype & 0xff
+	case SQL_TYPE_BLOB:
+		if x.sqlsubtype == 1 {
+			ct.CharacterSetID = x.sqlscale & 0xff
+			ct.Scale = 0
+		}
+	}
+	if x.hasPrecisionScale() || x.sqltype == SQL_TYPE_DEC64 || x.sqltype == SQL_TYPE_DEC128 {
+		ct.Precision = defaultPrecision[x.sqltype]
+	}
+	return ct
+}
+
+// columnTypes returns the descriptors of the result columns.
+func (stmt *firebirdsqlStmt) columnTypes() []ColumnType {
+	names := stmt.columnNames()
+	types := make([]ColumnType, len(stmt.xsqlda))
+	for i := range stmt.xsqlda {
+		types[i] = newColumnType(&stmt.xsqlda[i], names[i])
+	}
+	return types
+}
+
+// ColumnTypes prepares query and returns the descriptors of its result columns
+// without executing it, see Describe.
+func ColumnTypes(ctx context.Context, db *sql.DB, query string) ([]ColumnType, error) {
+	desc, err := Describe(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	return desc.Outputs, nil
+}
+
+// ColumnTyper is implemented by the driver.Rows and driver.Stmt of this driver, which are
+// used through sql.Conn.Raw, to describe the result columns of an open query or a prepared statement:
+//
+//	err = conn.Raw(func(driverConn interface{}) error {
+//		rows, err := driverConn.(driver.QueryerContext).QueryContext(ctx, query, nil)
+//		if err != nil {
+//			return err
+//		}
+//		defer rows.Close()
+//		types, err := rows.(firebirdsql.ColumnTyper).ColumnTypes(ctx)
+//		...
+//	})
+type ColumnTyper interface {
+	ColumnTypes(ctx context.Context) ([]ColumnType, error)
+}
+
+// ColumnTypes returns the descriptors of the result columns in the transaction of the statement.
+func (stmt *firebirdsqlStmt) ColumnTypes(ctx context.Context) ([]ColumnType, error) {
+	types := stmt.columnTypes()
+	if err := stmt.tx.fc.fieldPrecisions(ctx, types); err != nil {
+		return nil, err
+	}
+	return types, nil
+}
+
+// ColumnTypes returns the descriptors of the result columns in the transaction of the rows.
+func (rows *firebirdsqlRows) ColumnTypes(ctx context.Context) ([]ColumnType, error) {
+	return rows.stmt.ColumnTypes(ctx)
+}
+
+// fieldPrecisions sets the declared precision of scaled number columns from the domains of the fields
+// by a query in the current transaction.
+func (fc *firebirdsqlConn) fieldPrecisions(ctx context.Context, types []ColumnType) error {
+	var conditions []string
+	var args []driver.NamedValue
+	for _, ct := range types {
+		if ct.Precision == 0 || ct.Scale == 0 || ct.Relation == "" || ct.Field == "" {
+			continue
+		}
+		conditions = append(conditions, "(rf.rdb$relation_name = ? AND rf.rdb$field_name = ?)")
+		args = append(args,
+			driver.NamedValue{Ordinal: len(args) + 1, Value: ct.Relation},
+			driver.NamedValue{Ordinal: len(args) + 2, Value: ct.Field})
+	}
+	if len(conditions) == 0 {
+		return nil
+	}
+	rows, err := fc.query(ctx, `
+		SELECT TRIM(rf.rdb$relation_name), TRIM(rf.rdb$field_name), f.rdb$field_precision
+		FROM rdb$relation_fields rf
+		JOIN rdb$fields f ON f.rdb$field_name = rf.rdb$field_source
+		WHERE `+strings.Join(conditions, " OR "), args)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// procedure outputs and fields of derived tables are not found
+	precisions := make(map[[2]string]int)
+	dest := make([]driver.Value, 3)
+	for {
+		if err = rows.Next(dest); err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		relation, _ := dest[0].(string)
+		field, _ := dest[1].(string)
+		if precision, ok := dest[2].(int16); ok && precision > 0 {
+			precisions[[2]string{relation, field}] = int(precision)
+		}
+	}
+	for i, ct := range types {
+		if precision, ok := precisions[[2]string{ct.Relation, ct.Field}]; ok && ct.Scale != 0 {
+			types[i].Precision = precision
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"testing"
+)
+
+func TestNewColumnType(t *testing.T) {
+	ct := newColumnType(&xSQLVAR{sqltype: SQL_TYPE_INT64, sqlscale: -2, sqlsubtype: 1, sqllen: 8, fieldname: "PRICE", relname: "ITEMS", ownname: "SYSDBA", aliasname: "P"}, "P")
+	if ct.Name != "P" || ct.Field != "PRICE" || ct.Relation != "ITEMS" || ct.Owner != "SYSDBA" {
+		t.Errorf("Incorrect names: %+v", ct)
+	}
+	if ct.DatabaseTypeName != "INT64" || ct.Scale != -2 || ct.Precision != 18 || ct.CharacterSetID != 0 {
+		t.Errorf("Incorrect type: %+v", ct)
+	}
+
+	ct = newColumnType(&xSQLVAR{sqltype: SQL_TYPE_VARYING, sqlsubtype: 4, sqllen: 40, null_ok: true}, "S")
+	if ct.CharacterSetID != 4 || ct.Length != 40 || ct.Precision != 0 || !ct.Nullable {
+		t.Errorf("Incorrect varchar: %+v", ct)
+	}
+
+	ct = newColumnType(&xSQLVAR{sqltype: SQL_TYPE_BLOB, sqlsubtype: 1, sqlscale: 4}, "B")
+	if ct.CharacterSetID != 4 || ct.Scale != 0 {
+		t.Errorf("Incorrect text blob: %+v", ct)
+	}
+}
+
+func TestDescribeStatement(t *testing.T) {
+	stmt := &firebirdsqlStmt{
+		tx:         &firebirdsqlTx{fc: &firebirdsqlConn{columnNameToLower: true}},
+		stmtType:   isc_info_sql_stmt_select,
+		xsqlda:     []xSQLVAR{{sqltype: SQL_TYPE_LONG, aliasname: "ID", fieldname: "ID", relname: "FOO"}},
+		bindXsqlda: []xSQLVAR{{sqltype: SQL_TYPE_VARYING, sqllen: 10}, {sqltype: SQL_TYPE_LONG}},
+		paramNames: []string{"name", "id"},
+	}
+	desc := stmt.describe()
+	if desc.StatementType != "SELECT" || !desc.HasCursor {
+		t.Errorf("Incorrect statement type: %+v", desc)
+	}
+	if len(desc.Outputs) != 1 || desc.Outputs[0].Name != "id" || desc.Outputs[0].Relation != "FOO" {
+		t.Errorf("Incorrect outputs: %+v", desc.Outputs)
+	}
+	if len(desc.Inputs) != 2 || desc.Inputs[0].Name != "name" || desc.Inputs[0].DatabaseTypeName != "VARYING" || desc.Inputs[1].Name != "id" {
+		t.Errorf("Incorrect inputs: %+v", desc.Inputs)
+	}
+}
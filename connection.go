@@ -25,8 +25,12 @@ package firebirdsql
 
 import (
 	"context"
+	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"math/big"
+	"strconv"
+	"strings"
 )
 
 type firebirdsqlConn struct {
@@ -41,27 +45,44 @@ type firebirdsqlConn struct {
 	clientPublic      *big.Int
 	clientSecret      *big.Int
 	transHandles      []int32
+	txOptions         TransactionOptions
+	stmtCache         *stmtCache
+	fetchSize         int
+	insertKeys        map[string]string // generated key column by relation, see WithLastInsertId
+	arrayDescs        map[string]*arrayDesc
 }
 
-func (fc *firebirdsqlConn) begin(isolationLevel int) (driver.Tx, error) {
-	tx, err := newFirebirdsqlTx(fc, isolationLevel, false)
-	fc.tx = tx
-	return driver.Tx(tx), err
+func (fc *firebirdsqlConn) begin(opts TransactionOptions) (driver.Tx, error) {
+	// End the implicit transaction and reuse fc.tx, prepared statements refer to it.
+	err := fc.tx.commit(false)
+	if err != nil {
+		return nil, err
+	}
+	fc.tx.opts = opts
+	fc.tx.isAutocommit = false
+	err = fc.tx.begin()
+	if err != nil {
+		fc.tx.reset()
+		return nil, err
+	}
+	return driver.Tx(fc.tx), nil
 }
 
 func (fc *firebirdsqlConn) Begin() (driver.Tx, error) {
-	return fc.begin(ISOLATION_LEVEL_READ_COMMITED)
+	return fc.begin(fc.txOptions)
 }
 
 func (fc *firebirdsqlConn) Close() (err error) {
 	for _, h := range fc.transHandles {
 		fc.wp.opRollback(h)
+		_, _, _, err = fc.wp.opResponse()
+		if err != nil {
+			return
+		}
 	}
+	fc.transHandles = nil
+	fc.tx.isActive = false
 
-	_, _, _, err = fc.wp.opResponse()
-	if err != nil {
-		return
-	}
 	fc.wp.opDetach()
 	_, _, _, err = fc.wp.opResponse()
 	fc.wp.conn.Close()
@@ -76,37 +97,193 @@ func (fc *firebirdsqlConn) Prepare(query string) (driver.Stmt, error) {
 	return fc.prepare(context.Background(), query)
 }
 
-func (fc *firebirdsqlConn) exec(ctx context.Context, query string, args []driver.Value) (result driver.Result, err error) {
-	stmt, err := fc.prepare(ctx, query)
+func (fc *firebirdsqlConn) exec(ctx context.Context, query string, args []driver.NamedValue) (result driver.Result, err error) {
+	if len(args) == 0 && isImmediate(tokenizeSQL(query)) {
+		// cached statements may lock the objects to be changed
+		fc.clearStmtCache()
+		if err = fc.execImmediate(query); err != nil {
+			return
+		}
+		if fc.isAutocommit && fc.tx.isAutocommit {
+			err = fc.tx.Commit()
+		}
+		return &firebirdsqlResult{}, err
+	}
+
+	var insertId *sql.NullInt64
+	if lastInsertIdFromContext(ctx) {
+		var key string
+		if key, err = fc.insertKeyColumn(ctx, query); err != nil {
+			return
+		}
+		if key != "" {
+			query = trimTerminator(query) + "\nRETURNING \"" + strings.Replace(key, `"`, `""`, -1) + `"`
+			insertId = new(sql.NullInt64)
+			args = append(args[:len(args):len(args)], driver.NamedValue{Ordinal: len(args) + 1, Value: sql.Out{Dest: insertId}})
+		}
+	}
+
+	var stmt *firebirdsqlStmt
+	if len(args) == 0 && fc.wp.acceptType == ptype_lazy_send && !fc.stmtCache.contains(query) && isPlainDML(tokenizeSQL(query)) {
+		// allocate, prepare and execute in a round trip
+		stmt, result, err = execNewStmt(fc, query)
+		if err != nil {
+			return
+		}
+	} else {
+		var cached bool
+		stmt, cached, err = fc.prepareCached(ctx, query)
+		if err != nil {
+			return
+		}
+		if stmt.stmtType == isc_info_sql_stmt_ddl {
+			// cached statements may lock the objects to be changed
+			fc.clearStmtCache()
+		}
+		result, err = stmt.exec(ctx, args)
+		if cached && hasGDSCode(err, isc_obsolete_metadata) {
+			stmt.Close()
+			stmt, err = newFirebirdsqlStmt(fc, query)
+			if err != nil {
+				return
+			}
+			result, err = stmt.exec(ctx, args)
+		}
+	}
+	if err == nil && insertId != nil {
+		result.(*firebirdsqlResult).lastInsertId = *insertId
+	}
+	if err == nil && fc.isAutocommit && fc.tx.isAutocommit {
+		fc.tx.Commit()
+	}
+	fc.releaseStmt(stmt)
+	return
+}
+
+// execImmediate executes query without preparing a statement.
+func (fc *firebirdsqlConn) execImmediate(query string) (err error) {
+	if err = fc.tx.beginIfNeeded(); err != nil {
+		return
+	}
+	fc.wp.opExecImmediate(fc.tx.transHandle, query)
+	_, _, _, err = fc.wp.opResponse()
+	return
+}
+
+// execImmediateCommitted executes query in a server transaction of its own and commits it,
+// as isql does with DDL statements while AUTODDL is ON.
+func (fc *firebirdsqlConn) execImmediateCommitted(query string) (err error) {
+	opts := fc.txOptions
+	opts.ReadOnly = false
+	tpb, err := opts.tpb()
 	if err != nil {
 		return
 	}
-	result, err = stmt.(*firebirdsqlStmt).exec(ctx, args)
+	fc.wp.opTransaction(tpb)
+	transHandle, _, _, err := fc.wp.opResponse()
 	if err != nil {
 		return
 	}
-	if fc.isAutocommit && fc.tx.isAutocommit {
-		fc.tx.Commit()
+	fc.wp.opExecImmediate(transHandle, query)
+	_, _, _, err = fc.wp.opResponse()
+	if err == nil {
+		fc.wp.opCommit(transHandle)
+		_, _, _, err = fc.wp.opResponse()
+	}
+	if err != nil {
+		fc.wp.opRollback(transHandle)
+		fc.wp.opResponse()
 	}
-	stmt.Close()
 	return
 }
 
+// ExecImmediate executes a statement without parameters and results on conn
+// in a round trip, without allocating and preparing a statement.
+// The number of affected rows is not reported.
+func ExecImmediate(ctx context.Context, conn *sql.Conn, query string) error {
+	return rawConn(conn, func(fc *firebirdsqlConn) error {
+		err := fc.execImmediate(query)
+		if err == nil && fc.isAutocommit && fc.tx.isAutocommit {
+			err = fc.tx.Commit()
+		}
+		return err
+	})
+}
+
 func (fc *firebirdsqlConn) Exec(query string, args []driver.Value) (result driver.Result, err error) {
-	return fc.exec(context.Background(), query, args)
+	return fc.exec(context.Background(), query, valuesToNamedValues(args))
 }
 
-func (fc *firebirdsqlConn) query(ctx context.Context, query string, args []driver.Value) (rows driver.Rows, err error) {
-	stmt, err := fc.prepare(ctx, query)
+func (fc *firebirdsqlConn) query(ctx context.Context, query string, args []driver.NamedValue) (rows driver.Rows, err error) {
+	stmt, cached, err := fc.prepareCached(ctx, query)
 	if err != nil {
 		return
 	}
-	rows, err = stmt.(*firebirdsqlStmt).query(ctx, args)
+	rows, err = stmt.query(ctx, args)
+	if cached && hasGDSCode(err, isc_obsolete_metadata) {
+		stmt.Close()
+		stmt, err = newFirebirdsqlStmt(fc, query)
+		if err != nil {
+			return
+		}
+		rows, err = stmt.query(ctx, args)
+	}
+	if err != nil {
+		fc.releaseStmt(stmt)
+		return nil, err
+	}
+	rows.(*firebirdsqlRows).releaseStmt = true
 	return
 }
 
 func (fc *firebirdsqlConn) Query(query string, args []driver.Value) (rows driver.Rows, err error) {
-	return fc.query(context.Background(), query, args)
+	return fc.query(context.Background(), query, valuesToNamedValues(args))
+}
+
+// prepareCached takes a statement for query out of the statement cache or prepares a new one.
+func (fc *firebirdsqlConn) prepareCached(ctx context.Context, query string) (stmt *firebirdsqlStmt, cached bool, err error) {
+	if stmt = fc.stmtCache.get(query); stmt != nil {
+		return stmt, true, nil
+	}
+	stmt, err = newFirebirdsqlStmt(fc, query)
+	return
+}
+
+// releaseStmt closes the cursor of a statement taken by prepareCached and returns it to the statement cache.
+func (fc *firebirdsqlConn) releaseStmt(stmt *firebirdsqlStmt) (err error) {
+	if stmt.cursorName != "" {
+		// do not reuse the cursor name
+		return stmt.Close()
+	}
+	dropped := false
+	for _, s := range fc.stmtCache.put(stmt) {
+		dropped = dropped || s == stmt
+		s.Close()
+	}
+	if !dropped {
+		err = stmt.closeCursor()
+	}
+	return
+}
+
+func (fc *firebirdsqlConn) clearStmtCache() {
+	for _, s := range fc.stmtCache.clear() {
+		s.Close()
+	}
+	// keys and array dimensions may be changed by DDL too
+	fc.insertKeys = nil
+	fc.arrayDescs = nil
+}
+
+// rawConn calls f with the driver connection of conn.
+func rawConn(conn *sql.Conn, f func(fc *firebirdsqlConn) error) error {
+	return conn.Raw(func(driverConn interface{}) error {
+		fc, ok := driverConn.(*firebirdsqlConn)
+		if !ok {
+			return errors.New("Not a firebirdsql connection")
+		}
+		return f(fc)
+	})
 }
 
 func (fc *firebirdsqlConn) loadTimeZoneId() {
@@ -755,6 +932,24 @@ func (fc *firebirdsqlConn) loadTimeZoneId() {
 
 func newFirebirdsqlConn(dsn string) (fc *firebirdsqlConn, err error) {
 	addr, dbName, user, password, options, err := parseDSN(dsn)
+	if err != nil {
+		return
+	}
+	txOptions, err := parseTransactionOptions(options)
+	if err != nil {
+		return
+	}
+	stmtCacheSize, err := strconv.Atoi(options["statement_cache_size"])
+	if err != nil {
+		return
+	}
+	fetchSize, err := strconv.Atoi(options["fetch_size"])
+	if err == nil && fetchSize < 0 {
+		err = errors.New("fetch_size must not be negative")
+	}
+	if err != nil {
+		return
+	}
 
 	wp, err := newWireProtocol(addr, options["timezone"])
 	if err != nil {
@@ -784,7 +979,10 @@ func newFirebirdsqlConn(dsn string) (fc *firebirdsqlConn, err error) {
 	fc.password = password
 	fc.columnNameToLower = column_name_to_lower
 	fc.isAutocommit = true
-	fc.tx, err = newFirebirdsqlTx(fc, ISOLATION_LEVEL_READ_COMMITED, fc.isAutocommit)
+	fc.txOptions = txOptions
+	fc.stmtCache = newStmtCache(stmtCacheSize)
+	fc.fetchSize = fetchSize
+	fc.tx, err = newFirebirdsqlTx(fc, fc.txOptions, fc.isAutocommit)
 	fc.clientPublic = clientPublic
 	fc.clientSecret = clientSecret
 
@@ -796,6 +994,24 @@ func newFirebirdsqlConn(dsn string) (fc *firebirdsqlConn, err error) {
 func createFirebirdsqlConn(dsn string) (fc *firebirdsqlConn, err error) {
 	// Create Database
 	addr, dbName, user, password, options, err := parseDSN(dsn)
+	if err != nil {
+		return
+	}
+	txOptions, err := parseTransactionOptions(options)
+	if err != nil {
+		return
+	}
+	stmtCacheSize, err := strconv.Atoi(options["statement_cache_size"])
+	if err != nil {
+		return
+	}
+	fetchSize, err := strconv.Atoi(options["fetch_size"])
+	if err == nil && fetchSize < 0 {
+		err = errors.New("fetch_size must not be negative")
+	}
+	if err != nil {
+		return
+	}
 
 	wp, err := newWireProtocol(addr, options["timezone"])
 	if err != nil {
@@ -824,7 +1040,10 @@ func createFirebirdsqlConn(dsn string) (fc *firebirdsqlConn, err error) {
 	fc.password = password
 	fc.columnNameToLower = column_name_to_lower
 	fc.isAutocommit = true
-	fc.tx, err = newFirebirdsqlTx(fc, ISOLATION_LEVEL_READ_COMMITED, fc.isAutocommit)
+	fc.txOptions = txOptions
+	fc.stmtCache = newStmtCache(stmtCacheSize)
+	fc.fetchSize = fetchSize
+	fc.tx, err = newFirebirdsqlTx(fc, fc.txOptions, fc.isAutocommit)
 	fc.clientPublic = clientPublic
 	fc.clientSecret = clientSecret
 
@@ -33,6 +33,8 @@ const (
 
 	// Protocol Version
 	PROTOCOL_VERSION13 = 13
+	PROTOCOL_VERSION16 = 16 // Firebird 4.0
+	PROTOCOL_VERSION18 = 18 // Firebird 5.0
 
 	CNCT_user              = 1
 	CNCT_passwd            = 2
@@ -149,29 +151,31 @@ const (
 	isc_info_svc_running            = 67
 	isc_info_svc_get_users          = 68
 
-	isc_tpb_version1         = 1
-	isc_tpb_version3         = 3
-	isc_tpb_consistency      = 1
-	isc_tpb_concurrency      = 2
-	isc_tpb_shared           = 3
-	isc_tpb_protected        = 4
-	isc_tpb_exclusive        = 5
-	isc_tpb_wait             = 6
-	isc_tpb_nowait           = 7
-	isc_tpb_read             = 8
-	isc_tpb_write            = 9
-	isc_tpb_lock_read        = 10
-	isc_tpb_lock_write       = 11
-	isc_tpb_verb_time        = 12
-	isc_tpb_commit_time      = 13
-	isc_tpb_ignore_limbo     = 14
-	isc_tpb_read_committed   = 15
-	isc_tpb_autocommit       = 16
-	isc_tpb_rec_version      = 17
-	isc_tpb_no_rec_version   = 18
-	isc_tpb_restart_requests = 19
-	isc_tpb_no_auto_undo     = 20
-	isc_tpb_lock_timeout     = 21
+	isc_tpb_version1           = 1
+	isc_tpb_version3           = 3
+	isc_tpb_consistency        = 1
+	isc_tpb_concurrency        = 2
+	isc_tpb_shared             = 3
+	isc_tpb_protected          = 4
+	isc_tpb_exclusive          = 5
+	isc_tpb_wait               = 6
+	isc_tpb_nowait             = 7
+	isc_tpb_read               = 8
+	isc_tpb_write              = 9
+	isc_tpb_lock_read          = 10
+	isc_tpb_lock_write         = 11
+	isc_tpb_verb_time          = 12
+	isc_tpb_commit_time        = 13
+	isc_tpb_ignore_limbo       = 14
+	isc_tpb_read_committed     = 15
+	isc_tpb_autocommit         = 16
+	isc_tpb_rec_version        = 17
+	isc_tpb_no_rec_version     = 18
+	isc_tpb_restart_requests   = 19
+	isc_tpb_no_auto_undo       = 20
+	isc_tpb_lock_timeout       = 21
+	isc_tpb_read_consistency   = 22
+	isc_tpb_at_snapshot_number = 23
 
 	// Service Parameter Block parameter
 	isc_spb_version1              = 1
@@ -314,29 +318,52 @@ const (
 	isc_info_tra_isolation          = 8
 	isc_info_tra_access             = 9
 	isc_info_tra_lock_timeout       = 10
+	fb_info_tra_dbpath              = 11
+	fb_info_tra_snapshot_number     = 12
+
+	// isc_info_tra_isolation values
+	isc_info_tra_consistency    = 1
+	isc_info_tra_concurrency    = 2
+	isc_info_tra_read_committed = 3
+
+	// isc_info_tra_read_committed options
+	isc_info_tra_no_rec_version   = 0
+	isc_info_tra_rec_version      = 1
+	isc_info_tra_read_consistency = 2
+
+	// isc_info_tra_access values
+	isc_info_tra_readonly  = 0
+	isc_info_tra_readwrite = 1
 
 	// SQL information items
-	isc_info_sql_select        = 4
-	isc_info_sql_bind          = 5
-	isc_info_sql_num_variables = 6
-	isc_info_sql_describe_vars = 7
-	isc_info_sql_describe_end  = 8
-	isc_info_sql_sqlda_seq     = 9
-	isc_info_sql_message_seq   = 10
-	isc_info_sql_type          = 11
-	isc_info_sql_sub_type      = 12
-	isc_info_sql_scale         = 13
-	isc_info_sql_length        = 14
-	isc_info_sql_null_ind      = 15
-	isc_info_sql_field         = 16
-	isc_info_sql_relation      = 17
-	isc_info_sql_owner         = 18
-	isc_info_sql_alias         = 19
-	isc_info_sql_sqlda_start   = 20
-	isc_info_sql_stmt_type     = 21
-	isc_info_sql_get_plan      = 22
-	isc_info_sql_records       = 23
-	isc_info_sql_batch_fetch   = 24
+	isc_info_sql_select          = 4
+	isc_info_sql_bind            = 5
+	isc_info_sql_num_variables   = 6
+	isc_info_sql_describe_vars   = 7
+	isc_info_sql_describe_end    = 8
+	isc_info_sql_sqlda_seq       = 9
+	isc_info_sql_message_seq     = 10
+	isc_info_sql_type            = 11
+	isc_info_sql_sub_type        = 12
+	isc_info_sql_scale           = 13
+	isc_info_sql_length          = 14
+	isc_info_sql_null_ind        = 15
+	isc_info_sql_field           = 16
+	isc_info_sql_relation        = 17
+	isc_info_sql_owner           = 18
+	isc_info_sql_alias           = 19
+	isc_info_sql_sqlda_start     = 20
+	isc_info_sql_stmt_type       = 21
+	isc_info_sql_get_plan        = 22
+	isc_info_sql_records         = 23
+	isc_info_sql_batch_fetch     = 24
+	isc_info_sql_relation_alias  = 25
+	isc_info_sql_explain_plan    = 26
+	isc_info_sql_stmt_flags      = 27
+	isc_info_sql_stmt_blob_align = 30
+
+	// isc_info_sql_stmt_flags
+	stmt_flag_has_cursor = 1
 
 	isc_info_sql_stmt_select         = 1
 	isc_info_sql_stmt_insert         = 2
@@ -394,10 +421,14 @@ const (
 	op_que_events         = 48
 	op_cancel_events      = 49
 	op_commit_retaining   = 50
+	op_prepare2           = 51
 	op_event              = 52
 	op_connect_request    = 53
 	op_aux_connect        = 53
 	op_create_blob2       = 57
+	op_get_slice          = 58
+	op_put_slice          = 59
+	op_slice              = 60
 	op_allocate_statement = 62
 	op_execute            = 63
 	op_execute_immediate  = 64
@@ -405,6 +436,7 @@ const (
 	op_fetch_response     = 66
 	op_free_statement     = 67
 	op_prepare_statement  = 68
+	op_set_cursor         = 69
 	op_info_sql           = 70
 	op_dummy              = 71
 	op_execute2           = 76
@@ -428,6 +460,53 @@ const (
 	op_crypt                = 96
 	op_crypt_key_callback   = 97
 	op_cond_accept          = 98
+
+	// FB4
+	op_batch_create = 99
+	op_batch_msg    = 100
+	op_batch_exec   = 101
+	op_batch_rls    = 102
+	op_batch_cs     = 103
+
+	op_batch_blob_stream = 105
+
+	// slice description language
+	isc_sdl_version1      = 1
+	isc_sdl_eoc           = 255
+	isc_sdl_relation      = 2
+	isc_sdl_field         = 4
+	isc_sdl_struct        = 6
+	isc_sdl_variable      = 7
+	isc_sdl_scalar        = 8
+	isc_sdl_tiny_integer  = 9
+	isc_sdl_short_integer = 10
+	isc_sdl_long_integer  = 11
+	isc_sdl_do2           = 34
+	isc_sdl_do1           = 35
+	isc_sdl_element       = 36
+
+	// batch parameters block (IBatch)
+	batch_version1            = 1
+	batch_tag_multierror      = 1
+	batch_tag_record_counts   = 2
+	batch_tag_buffer_bytes    = 3
+	batch_tag_blob_policy     = 4
+	batch_tag_detailed_errors = 5
+	batch_blob_none           = 0
+	batch_blob_stream         = 3
+	// FB5
+	op_fetch_scroll = 112
+
+	// op_fetch_scroll operations
+	fetch_next     = 0
+	fetch_prior    = 1
+	fetch_first    = 2
+	fetch_last     = 3
+	fetch_absolute = 4
+	fetch_relative = 5
+
+	// op_execute cursor flags
+	cursor_type_scrollable = 1
 )
 
 const (
@@ -436,4 +515,12 @@ const (
 	ISOLATION_LEVEL_REPEATABLE_READ
 	ISOLATION_LEVEL_SERIALIZABLE
 	ISOLATION_LEVEL_READ_COMMITED_RO
+	ISOLATION_LEVEL_READ_CONSISTENCY // Firebird 4.0+
+)
+
+// Table reservation modes
+const (
+	RESERVATION_SHARED    = isc_tpb_shared
+	RESERVATION_PROTECTED = isc_tpb_protected
+	RESERVATION_EXCLUSIVE = isc_tpb_exclusive
 )
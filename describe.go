@@ -0,0 +1,106 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"context"
+	"database/sql"
+)
+
+var statementTypeName = map[int32]string{
+	isc_info_sql_stmt_select:         "SELECT",
+	isc_info_sql_stmt_insert:         "INSERT",
+	isc_info_sql_stmt_update:         "UPDATE",
+	isc_info_sql_stmt_delete:         "DELETE",
+	isc_info_sql_stmt_ddl:            "DDL",
+	isc_info_sql_stmt_get_segment:    "GET SEGMENT",
+	isc_info_sql_stmt_put_segment:    "PUT SEGMENT",
+	isc_info_sql_stmt_exec_procedure: "EXECUTE PROCEDURE",
+	isc_info_sql_stmt_start_trans:    "START TRANSACTION",
+	isc_info_sql_stmt_commit:         "COMMIT",
+	isc_info_sql_stmt_rollback:       "ROLLBACK",
+	isc_info_sql_stmt_select_for_upd: "SELECT FOR UPDATE",
+	isc_info_sql_stmt_set_generator:  "SET GENERATOR",
+	isc_info_sql_stmt_savepoint:      "SAVEPOINT",
+}
+
+// Description is the result of preparing a statement.
+type Description struct {
+	StatementType string // "SELECT", "INSERT", "EXECUTE PROCEDURE", "DDL", ...
+	HasCursor     bool   // rows are fetched, including DML with RETURNING of multiple rows
+	Inputs        []ColumnType
+	Outputs       []ColumnType
+}
+
+// describe returns the descriptors of the parameters and the result columns.
+// Inputs are named by the :name parameters if used.
+func (stmt *firebirdsqlStmt) describe() *Description {
+	desc := &Description{
+		StatementType: statementTypeName[stmt.stmtType],
+		HasCursor:     stmt.hasCursor(),
+		Inputs:        make([]ColumnType, len(stmt.bindXsqlda)),
+		Outputs:       stmt.columnTypes(),
+	}
+	for i := range stmt.bindXsqlda {
+		name := ""
+		if i < len(stmt.paramNames) {
+			name = stmt.paramNames[i]
+		}
+		desc.Inputs[i] = newColumnType(&stmt.bindXsqlda[i], name)
+	}
+	return desc
+}
+
+// Describe prepares query in a read-only transaction, which is rolled back,
+// and returns its statement type, parameters and result columns without executing it.
+// A query referring to objects which do not exist or mismatched types is reported as the error of the server.
+func Describe(ctx context.Context, db *sql.DB, query string) (*Description, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var desc *Description
+	err = rawConn(conn, func(fc *firebirdsqlConn) error {
+		stmt, err := newFirebirdsqlStmt(fc, query)
+		if err != nil {
+			return err
+		}
+		desc = stmt.describe()
+		if err = stmt.Close(); err != nil {
+			return err
+		}
+		return fc.fieldPrecisions(ctx, desc.Outputs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
@@ -0,0 +1,136 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DistributedTx is a transaction spanning several attachments.
+// It is committed with two-phase commit: every attachment is prepared, then committed.
+//
+// Statements run through the given *sql.Conn (not through a *sql.Tx) join the transaction
+// until it is committed or rolled back.
+type DistributedTx struct {
+	conns    []*sql.Conn
+	prepared bool
+	done     bool
+}
+
+// BeginDistributed starts a transaction with opts on every connection.
+func BeginDistributed(conns []*sql.Conn, opts TransactionOptions) (*DistributedTx, error) {
+	if len(conns) == 0 {
+		return nil, errors.New("No connections for distributed transaction")
+	}
+	dtx := &DistributedTx{}
+	for _, conn := range conns {
+		err := rawConn(conn, func(fc *firebirdsqlConn) error {
+			if !fc.tx.isAutocommit {
+				return errors.New("Connection is already in a transaction")
+			}
+			_, err := fc.begin(opts)
+			return err
+		})
+		if err != nil {
+			dtx.Rollback()
+			return nil, err
+		}
+		dtx.conns = append(dtx.conns, conn)
+	}
+	return dtx, nil
+}
+
+// Prepare runs the first phase of two-phase commit with an optional message
+// that is stored in RDB$TRANSACTIONS for limbo transaction recovery.
+// If an attachment fails to prepare, the whole transaction is rolled back.
+func (dtx *DistributedTx) Prepare(message []byte) error {
+	if dtx.done {
+		return sql.ErrTxDone
+	}
+	if dtx.prepared {
+		return nil
+	}
+	for _, conn := range dtx.conns {
+		err := rawConn(conn, func(fc *firebirdsqlConn) error {
+			return fc.tx.prepare(message)
+		})
+		if err != nil {
+			dtx.Rollback()
+			return err
+		}
+	}
+	dtx.prepared = true
+	return nil
+}
+
+// Commit prepares the transaction unless Prepare was called, then commits every attachment.
+func (dtx *DistributedTx) Commit() error {
+	err := dtx.Prepare(nil)
+	if err != nil {
+		return err
+	}
+	dtx.done = true
+
+	var failed []string
+	for i, conn := range dtx.conns {
+		err := rawConn(conn, func(fc *firebirdsqlConn) error {
+			err := fc.tx.commit(false)
+			if err != nil {
+				// Left in limbo on the server, it has to be resolved by gfix.
+				fc.tx.end()
+			}
+			fc.tx.reset()
+			return err
+		})
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%d: %v", i, err))
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(fmt.Sprintf("Distributed transaction is in limbo on connections %s", strings.Join(failed, ", ")))
+	}
+	return nil
+}
+
+// Rollback rolls back every attachment.
+func (dtx *DistributedTx) Rollback() (err error) {
+	if dtx.done {
+		return sql.ErrTxDone
+	}
+	dtx.done = true
+	for _, conn := range dtx.conns {
+		e := rawConn(conn, func(fc *firebirdsqlConn) error {
+			err := fc.tx.rollback(false)
+			fc.tx.reset()
+			return err
+		})
+		if err == nil {
+			err = e
+		}
+	}
+	return
+}
@@ -1,5 +1,3 @@
-// +build go1.8
-
 /*******************************************************************************
 The MIT License (MIT)
 
@@ -30,47 +28,37 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"errors"
-	"sort"
 )
 
 func (stmt *firebirdsqlStmt) ExecContext(ctx context.Context, namedargs []driver.NamedValue) (result driver.Result, err error) {
-	sort.SliceStable(namedargs, func(i, j int) bool {
-		return namedargs[i].Ordinal < namedargs[j].Ordinal
-	})
-	args := make([]driver.Value, len(namedargs))
-	for i, nv := range namedargs {
-		args[i] = nv.Value
-	}
-
-	return stmt.exec(ctx, args)
+	return stmt.exec(ctx, namedargs)
 }
 
 func (stmt *firebirdsqlStmt) QueryContext(ctx context.Context, namedargs []driver.NamedValue) (rows driver.Rows, err error) {
-	args := make([]driver.Value, len(namedargs))
-	for i, nv := range namedargs {
-		args[i] = nv.Value
-	}
-
-	return stmt.query(ctx, args)
+	return stmt.query(ctx, namedargs)
 }
 
 func (fc *firebirdsqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	txOptions, ok := transactionOptionsFromContext(ctx)
+	if !ok {
+		txOptions = fc.txOptions
+	}
 	if opts.ReadOnly {
-		return fc.begin(ISOLATION_LEVEL_READ_COMMITED_RO)
+		txOptions.ReadOnly = true
 	}
 
 	switch (sql.IsolationLevel)(opts.Isolation) {
 	case sql.LevelDefault:
-		return fc.begin(ISOLATION_LEVEL_READ_COMMITED)
 	case sql.LevelReadCommitted:
-		return fc.begin(ISOLATION_LEVEL_READ_COMMITED)
+		txOptions.Isolation = ISOLATION_LEVEL_READ_COMMITED
 	case sql.LevelRepeatableRead:
-		return fc.begin(ISOLATION_LEVEL_REPEATABLE_READ)
+		txOptions.Isolation = ISOLATION_LEVEL_REPEATABLE_READ
 	case sql.LevelSerializable:
-		return fc.begin(ISOLATION_LEVEL_SERIALIZABLE)
+		txOptions.Isolation = ISOLATION_LEVEL_SERIALIZABLE
 	default:
+		return nil, errors.New("This isolation level is not supported.")
 	}
-	return nil, errors.New("This isolation level is not supported.")
+	return fc.begin(txOptions)
 }
 
 func (fc *firebirdsqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
@@ -78,11 +66,7 @@ func (fc *firebirdsqlConn) PrepareContext(ctx context.Context, query string) (dr
 }
 
 func (fc *firebirdsqlConn) ExecContext(ctx context.Context, query string, namedargs []driver.NamedValue) (result driver.Result, err error) {
-	args := make([]driver.Value, len(namedargs))
-	for i, nv := range namedargs {
-		args[i] = nv.Value
-	}
-	return fc.exec(ctx, query, args)
+	return fc.exec(ctx, query, namedargs)
 }
 
 func (fc *firebirdsqlConn) Ping(ctx context.Context) error {
@@ -93,9 +77,26 @@ func (fc *firebirdsqlConn) Ping(ctx context.Context) error {
 }
 
 func (fc *firebirdsqlConn) QueryContext(ctx context.Context, query string, namedargs []driver.NamedValue) (rows driver.Rows, err error) {
-	args := make([]driver.Value, len(namedargs))
-	for i, nv := range namedargs {
-		args[i] = nv.Value
+	return fc.query(ctx, query, namedargs)
+}
+
+// CheckNamedValue accepts sql.Out for the outputs of EXECUTE PROCEDURE,
+// other arguments are converted by the default converter.
+func (fc *firebirdsqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	return checkNamedValue(nv)
+}
+
+func (stmt *firebirdsqlStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	return checkNamedValue(nv)
+}
+
+func checkNamedValue(nv *driver.NamedValue) error {
+	if _, ok := nv.Value.(sql.Out); ok {
+		return nil
+	}
+	if isArrayValue(nv.Value) {
+		// written to an ARRAY parameter
+		return nil
 	}
-	return fc.query(ctx, query, args)
+	return driver.ErrSkip
 }
@@ -1,5 +1,3 @@
-// +build go1.8
-
 /*******************************************************************************
 The MIT License (MIT)
 
@@ -141,3 +139,142 @@ func TestGo18(t *testing.T) {
 	}
 	conn.Close()
 }
+
+func TestNamedParams(t *testing.T) {
+	temppath := TempFileName("test_named_params_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_named_params (a integer, b varchar(30))")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Exec("INSERT INTO test_named_params (a, b) VALUES (:a, :b || ' :b')", sql.Named("b", "x"), sql.Named("a", 1))
+	if err != nil {
+		t.Fatalf("Error Insert: %v", err)
+	}
+
+	stmt, err := conn.Prepare("SELECT b FROM test_named_params WHERE a = :a OR a = :a + :offset")
+	if err != nil {
+		t.Fatalf("Error Prepare: %v", err)
+	}
+	defer stmt.Close()
+	var b string
+	err = stmt.QueryRow(sql.Named("offset", 0), sql.Named("a", 1)).Scan(&b)
+	if err != nil {
+		t.Fatalf("Error QueryRow: %v", err)
+	}
+	if b != "x :b" {
+		t.Fatalf("Incorrect value: %v", b)
+	}
+
+	err = stmt.QueryRow(sql.Named("a", 1)).Scan(&b)
+	if err == nil {
+		t.Fatalf("Missing argument is not detected")
+	}
+	_, err = conn.Exec("UPDATE test_named_params SET b = :b", sql.Named("b", "y"), sql.Named("c", "z"))
+	if err == nil || !strings.Contains(err.Error(), "not used") {
+		t.Fatalf("Unused argument is not detected: %v", err)
+	}
+}
+
+func TestProcedureArgs(t *testing.T) {
+	var r, io int
+	inputs, outs, err := procedureArgs([]string{"A", "B", "C"},
+		[]interface{}{sql.Named("b", 2), 1, sql.Out{Dest: &r}, sql.Named("total", sql.Out{Dest: &r})})
+	if err != nil {
+		t.Fatalf("Error procedureArgs: %v", err)
+	}
+	if len(inputs) != 2 || inputs[0] != 1 || inputs[1] != 2 || len(outs) != 2 {
+		t.Fatalf("Incorrect arguments: %v %v", inputs, outs)
+	}
+
+	io = 3
+	inputs, outs, err = procedureArgs([]string{"A"}, []interface{}{sql.Out{Dest: &io, In: true}})
+	if err != nil || len(inputs) != 1 || inputs[0] != 3 || len(outs) != 1 || outs[0].(sql.Out).In {
+		t.Fatalf("Incorrect InOut argument: %v %v %v", inputs, outs, err)
+	}
+
+	if _, _, err = procedureArgs([]string{"A", "B"}, []interface{}{sql.Named("b", 2)}); err == nil {
+		t.Fatalf("Missing input is not detected")
+	}
+	if _, _, err = procedureArgs([]string{"A"}, []interface{}{1, 2}); err == nil {
+		t.Fatalf("Too many inputs are not detected")
+	}
+
+	if normalizeIdentifier("foo") != "FOO" || normalizeIdentifier(`"Foo""s"`) != `Foo"s` {
+		t.Fatalf("Incorrect normalizeIdentifier()")
+	}
+}
+
+func TestProcedureOut(t *testing.T) {
+	temppath := TempFileName("test_procedure_out_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec(`
+		CREATE PROCEDURE test_divmod (a INTEGER, b INTEGER = 3)
+		RETURNS (q INTEGER, r INTEGER, msg VARCHAR(20))
+		AS
+		BEGIN
+			q = a / b;
+			r = mod(a, b);
+			msg = 'ok';
+		END`)
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	var q, r int
+	var msg string
+	_, err = conn.ExecContext(ctx, "EXECUTE PROCEDURE test_divmod(?, ?)", 7, 2,
+		sql.Out{Dest: &q}, sql.Named("msg", sql.Out{Dest: &msg}), sql.Out{Dest: &r})
+	if err != nil {
+		t.Fatalf("Error Exec: %v", err)
+	}
+	if q != 3 || r != 1 || msg != "ok" {
+		t.Fatalf("Incorrect outputs: %v %v %v", q, r, msg)
+	}
+
+	q, r = 0, 0
+	_, err = CallProcedure(ctx, conn, "test_divmod", sql.Named("a", 10), sql.Named("r", sql.Out{Dest: &r}), sql.Out{Dest: &q})
+	if err != nil {
+		t.Fatalf("Error CallProcedure: %v", err)
+	}
+	if q != 3 || r != 1 {
+		t.Fatalf("Incorrect outputs: %v %v", q, r)
+	}
+
+	stmt, err := conn.PrepareContext(ctx, "EXECUTE PROCEDURE test_divmod(?)")
+	if err != nil {
+		t.Fatalf("Error Prepare: %v", err)
+	}
+	defer stmt.Close()
+	q, r = 0, 0
+	if _, err = stmt.ExecContext(ctx, 11, sql.Out{Dest: &q}, sql.Out{Dest: &r}); err != nil {
+		t.Fatalf("Error Stmt.Exec: %v", err)
+	}
+	if q != 3 || r != 2 {
+		t.Fatalf("Incorrect outputs: %v %v", q, r)
+	}
+
+	if _, err = conn.ExecContext(ctx, "SELECT 1 FROM rdb$database", sql.Out{Dest: &q}); err == nil {
+		t.Fatalf("sql.Out for SELECT is not rejected")
+	}
+}
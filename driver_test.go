@@ -24,8 +24,10 @@ CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
 package firebirdsql
 
 import (
+	"context"
 	"crypto/rand"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/hex"
 	"fmt"
 	"os"
@@ -34,6 +36,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 func TempFileName(prefix string) string {
@@ -776,3 +780,643 @@ func TestGoIssue65(t *testing.T) {
 		t.Fatalf(field01)
 	}*/
 }
+
+func TestParamTypes(t *testing.T) {
+	temppath := TempFileName("test_param_types_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec(`
+        CREATE TABLE test_param_types (
+            b bigint,
+            n numeric(9,2),
+            d double precision,
+            dt date,
+            tm time,
+            f boolean,
+            bl blob sub_type text
+        )
+    `)
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+
+	stmt, err := conn.Prepare("INSERT INTO test_param_types (b, n, d, dt, tm, f, bl) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		t.Fatalf("Error Prepare: %v", err)
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(int64(1))
+	if err == nil {
+		t.Fatalf("Argument count mismatch is not detected")
+	}
+
+	_, err = stmt.Exec(int64(9007199254740993), "1234567.891", 1.5, time.Date(2021, 2, 3, 0, 0, 0, 0, time.Local), time.Date(0, 1, 1, 4, 5, 6, 0, time.Local), true, "blob")
+	if err != nil {
+		t.Fatalf("Error Insert: %v", err)
+	}
+
+	_, err = stmt.Exec(int64(1), "123456789.1", 1.5, nil, nil, nil, nil)
+	if err == nil {
+		t.Fatalf("Overflow is not detected")
+	}
+
+	var b int64
+	var n, d float64
+	var dt, tm time.Time
+	var f bool
+	var bl []byte
+	err = conn.QueryRow("SELECT b, n, d, dt, tm, f, bl FROM test_param_types").Scan(&b, &n, &d, &dt, &tm, &f, &bl)
+	if err != nil {
+		t.Fatalf("Error Select: %v", err)
+	}
+	if b != 9007199254740993 || n != 1234567.89 || d != 1.5 || dt.Day() != 3 || tm.Minute() != 5 || !f || string(bl) != "blob" {
+		t.Fatalf("Incorrect values: %v %v %v %v %v %v %v", b, n, d, dt, tm, f, string(bl))
+	}
+}
+
+func TestStatementCache(t *testing.T) {
+	temppath := TempFileName("test_statement_cache_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_statement_cache (a integer)")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath+"?statement_cache_size=2")
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	var n int
+	for i := 0; i < 3; i++ {
+		_, err = conn.Exec("INSERT INTO test_statement_cache (a) VALUES (?)", i)
+		if err != nil {
+			t.Fatalf("Error Insert: %v", err)
+		}
+		err = conn.QueryRow("SELECT count(*) FROM test_statement_cache").Scan(&n)
+		if err != nil {
+			t.Fatalf("Error QueryRow: %v", err)
+		}
+		if n != i+1 {
+			t.Fatalf("Incorrect count: %v", n)
+		}
+	}
+
+	// DDL on the same connection releases cached statements
+	_, err = conn.Exec("ALTER TABLE test_statement_cache ADD b integer")
+	if err != nil {
+		t.Fatalf("Error Alter: %v", err)
+	}
+	err = conn.QueryRow("SELECT count(*) FROM test_statement_cache WHERE b IS NULL").Scan(&n)
+	if err != nil || n != 3 {
+		t.Fatalf("Error QueryRow: %v %v", err, n)
+	}
+
+	// prepared statement can be queried again after rows are closed
+	stmt, err := conn.Prepare("SELECT a FROM test_statement_cache WHERE a >= ?")
+	if err != nil {
+		t.Fatalf("Error Prepare: %v", err)
+	}
+	defer stmt.Close()
+	for i := 0; i < 2; i++ {
+		rows, err := stmt.Query(1)
+		if err != nil {
+			t.Fatalf("Error Query: %v", err)
+		}
+		n = 0
+		for rows.Next() {
+			n++
+		}
+		rows.Close()
+		if n != 2 {
+			t.Fatalf("Incorrect count: %v", n)
+		}
+	}
+}
+
+func TestFetchSize(t *testing.T) {
+	temppath := TempFileName("test_fetch_size_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	query := "SELECT rdb$relation_id FROM rdb$relations"
+	for _, params := range []string{"?fetch_size=1", "?fetch_size=0"} {
+		conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath+params)
+		if err != nil {
+			t.Fatalf("Error sql.Open(): %v", err)
+		}
+		var expected, n int
+		conn.QueryRow("SELECT count(*) FROM rdb$relations").Scan(&expected)
+		rows, err := conn.QueryContext(WithFetchSize(context.Background(), 3), query)
+		if err != nil {
+			t.Fatalf("Error Query: %v", err)
+		}
+		for rows.Next() {
+			n++
+		}
+		rows.Close()
+		if n != expected {
+			t.Fatalf("Incorrect rows %v: %v != %v", params, n, expected)
+		}
+
+		n = 0
+		rows, err = conn.Query(query)
+		if err != nil {
+			t.Fatalf("Error Query: %v", err)
+		}
+		for rows.Next() {
+			n++
+		}
+		rows.Close()
+		if n != expected {
+			t.Fatalf("Incorrect rows %v: %v != %v", params, n, expected)
+		}
+		conn.Close()
+	}
+}
+
+func TestCursorName(t *testing.T) {
+	temppath := TempFileName("test_cursor_name_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_cursor (id INTEGER NOT NULL, n INTEGER)")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+	for i := 1; i <= 5; i++ {
+		conn.Exec("INSERT INTO test_cursor (id, n) VALUES (?, 0)", i)
+	}
+
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("Error BeginTx: %v", err)
+	}
+	rows, err := tx.QueryContext(WithCursorName(ctx, "CUR"), "SELECT id FROM test_cursor ORDER BY id FOR UPDATE")
+	if err != nil {
+		t.Fatalf("Error Query: %v", err)
+	}
+	for rows.Next() {
+		var id int
+		rows.Scan(&id)
+		if id%2 == 0 {
+			_, err = tx.Exec("DELETE FROM test_cursor WHERE CURRENT OF CUR")
+		} else {
+			_, err = tx.Exec("UPDATE test_cursor SET n = ? WHERE CURRENT OF CUR", id*10)
+		}
+		if err != nil {
+			t.Fatalf("Error positioned update: %v", err)
+		}
+	}
+	rows.Close()
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("Error Commit: %v", err)
+	}
+
+	var count, sum int
+	conn.QueryRow("SELECT count(*), sum(n) FROM test_cursor").Scan(&count, &sum)
+	if count != 3 || sum != 90 {
+		t.Fatalf("Incorrect result: %v %v", count, sum)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+TempFileName("test_plan_"))
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	plan, err := Plan(ctx, conn, "SELECT * FROM rdb$relations")
+	if err != nil {
+		t.Fatalf("Error Plan: %v", err)
+	}
+	if plan != "PLAN (RDB$RELATIONS NATURAL)" {
+		t.Fatalf("Incorrect plan: %q", plan)
+	}
+
+	plan, err = ExplainPlan(ctx, conn, "SELECT * FROM rdb$relations")
+	if err != nil {
+		t.Fatalf("Error ExplainPlan: %v", err)
+	}
+	if !strings.Contains(plan, "Full Scan") {
+		t.Fatalf("Incorrect explained plan: %q", plan)
+	}
+
+	if _, err = Plan(ctx, conn, "SELECT * FROM no_such_table"); err == nil {
+		t.Fatalf("Plan of an invalid query succeeded")
+	}
+}
+
+func TestExecRecordCounts(t *testing.T) {
+	temppath := TempFileName("test_record_counts_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_counts (id INTEGER NOT NULL PRIMARY KEY, n INTEGER)")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+	conn.Exec("INSERT INTO test_counts (id, n) VALUES (1, 0)")
+
+	ctx := context.Background()
+	c, err := conn.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Error Conn: %v", err)
+	}
+	defer c.Close()
+
+	res, err := Exec(ctx, c, `
+		MERGE INTO test_counts t
+		USING (SELECT 1 id FROM rdb$database UNION ALL SELECT 2 id FROM rdb$database) s
+		ON t.id = s.id
+		WHEN MATCHED THEN UPDATE SET n = 1
+		WHEN NOT MATCHED THEN INSERT (id, n) VALUES (s.id, 1)`)
+	if err != nil {
+		t.Fatalf("Error Exec: %v", err)
+	}
+	if res.InsertCount() != 1 || res.UpdateCount() != 1 || res.DeleteCount() != 0 {
+		t.Fatalf("Incorrect record counts: %v %v %v", res.InsertCount(), res.UpdateCount(), res.DeleteCount())
+	}
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Fatalf("Incorrect rows affected: %v", n)
+	}
+
+	r, err := conn.Exec("UPDATE OR INSERT INTO test_counts (id, n) VALUES (?, 2) MATCHING (id)", 3)
+	if err != nil {
+		t.Fatalf("Error Exec: %v", err)
+	}
+	if n, _ := r.RowsAffected(); n != 1 {
+		t.Fatalf("Incorrect rows affected: %v", n)
+	}
+}
+
+func TestReturningMultipleRows(t *testing.T) {
+	temppath := TempFileName("test_returning_rows_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_returning (id INTEGER NOT NULL, n INTEGER)")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+
+	var version string
+	conn.QueryRow("SELECT rdb$get_context('SYSTEM', 'ENGINE_VERSION') FROM rdb$database").Scan(&version)
+	if version < "5" {
+		t.Skip("DML RETURNING multiple rows requires Firebird 5.0")
+	}
+	for i := 1; i <= 5; i++ {
+		conn.Exec("INSERT INTO test_returning (id, n) VALUES (?, 0)", i)
+	}
+
+	rows, err := conn.Query("UPDATE test_returning SET n = id * 10 WHERE id > ? RETURNING id, n", 2)
+	if err != nil {
+		t.Fatalf("Error Query: %v", err)
+	}
+	sum := 0
+	for rows.Next() {
+		var id, n int
+		rows.Scan(&id, &n)
+		if n != id*10 {
+			t.Fatalf("Incorrect row: %v %v", id, n)
+		}
+		sum += id
+	}
+	rows.Close()
+	if sum != 12 {
+		t.Fatalf("Incorrect rows: %v", sum)
+	}
+
+	res, err := conn.Exec("DELETE FROM test_returning WHERE id <= ? RETURNING id", 3)
+	if err != nil {
+		t.Fatalf("Error Exec: %v", err)
+	}
+	if n, _ := res.RowsAffected(); n != 3 {
+		t.Fatalf("Incorrect rows affected: %v", n)
+	}
+
+	var id int
+	if err = conn.QueryRow("INSERT INTO test_returning (id, n) VALUES (6, 0) RETURNING id").Scan(&id); err != nil || id != 6 {
+		t.Fatalf("Incorrect singleton RETURNING: %v %v", id, err)
+	}
+}
+
+func TestExecImmediate(t *testing.T) {
+	temppath := TempFileName("test_exec_immediate_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	// executed by op_execute_immediate
+	if _, err = conn.Exec("CREATE TABLE test_immediate (id INTEGER NOT NULL PRIMARY KEY)"); err != nil {
+		t.Fatalf("Error Exec: %v", err)
+	}
+	if _, err = conn.Exec("CREATE SEQUENCE test_immediate_seq"); err != nil {
+		t.Fatalf("Error Exec: %v", err)
+	}
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	c, err := conn.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Error Conn: %v", err)
+	}
+	defer c.Close()
+	if err = ExecImmediate(ctx, c, "INSERT INTO test_immediate (id) VALUES (NEXT VALUE FOR test_immediate_seq)"); err != nil {
+		t.Fatalf("Error ExecImmediate: %v", err)
+	}
+	if err = ExecImmediate(ctx, c, "ALTER SEQUENCE test_immediate_seq RESTART WITH 10"); err != nil {
+		t.Fatalf("Error ExecImmediate: %v", err)
+	}
+
+	var n int
+	if err = conn.QueryRow("SELECT COUNT(*) FROM test_immediate").Scan(&n); err != nil || n != 1 {
+		t.Fatalf("Incorrect count: %v %v", n, err)
+	}
+	if err = conn.QueryRow("SELECT NEXT VALUE FOR test_immediate_seq FROM rdb$database").Scan(&n); err != nil || n < 10 {
+		t.Fatalf("Sequence is not restarted: %v %v", n, err)
+	}
+
+	if err = ExecImmediate(ctx, c, "DROP TABLE test_immediate_not_exist"); err == nil {
+		t.Fatalf("Error is not returned")
+	}
+}
+
+func TestColumnTypes(t *testing.T) {
+	temppath := TempFileName("test_column_types_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_column_types (id INTEGER NOT NULL, price NUMERIC(6, 2), name VARCHAR(10) CHARACTER SET UTF8)")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+
+	types, err := ColumnTypes(context.Background(), conn, "SELECT id, price p, name, 1 + 1 FROM test_column_types")
+	if err != nil {
+		t.Fatalf("Error ColumnTypes: %v", err)
+	}
+	if len(types) != 4 {
+		t.Fatalf("Incorrect column count: %v", len(types))
+	}
+	if types[0].Relation != "TEST_COLUMN_TYPES" || types[0].Field != "ID" || types[0].Owner != "SYSDBA" || types[0].Nullable {
+		t.Errorf("Incorrect ID: %+v", types[0])
+	}
+	if types[1].Name != "P" || types[1].Field != "PRICE" || types[1].Scale != -2 || types[1].Precision != 6 {
+		t.Errorf("Incorrect PRICE: %+v", types[1])
+	}
+	if types[2].CharacterSetID != 4 || types[2].Length != 40 {
+		t.Errorf("Incorrect NAME: %+v", types[2])
+	}
+	if types[3].Relation != "" || types[3].Field != "" {
+		t.Errorf("Incorrect expression: %+v", types[3])
+	}
+
+	ctx := context.Background()
+	c, err := conn.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Error Conn: %v", err)
+	}
+	defer c.Close()
+	err = c.Raw(func(driverConn interface{}) error {
+		rows, err := driverConn.(driver.QueryerContext).QueryContext(ctx, "SELECT name, price FROM test_column_types", nil)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		types, err = rows.(ColumnTyper).ColumnTypes(ctx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Error ColumnTypes of rows: %v", err)
+	}
+	if len(types) != 2 || types[0].CharacterSetID != 4 || types[1].Precision != 6 {
+		t.Errorf("Incorrect column types of rows: %+v", types)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	temppath := TempFileName("test_describe_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_describe (id INTEGER NOT NULL, name VARCHAR(20))")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	desc, err := Describe(ctx, conn, "SELECT id, name FROM test_describe WHERE id = ? AND name = ?")
+	if err != nil {
+		t.Fatalf("Error Describe: %v", err)
+	}
+	if desc.StatementType != "SELECT" || len(desc.Inputs) != 2 || len(desc.Outputs) != 2 {
+		t.Fatalf("Incorrect description: %+v", desc)
+	}
+	if desc.Inputs[0].SQLType != SQL_TYPE_LONG || desc.Inputs[1].SQLType != SQL_TYPE_VARYING {
+		t.Errorf("Incorrect inputs: %+v", desc.Inputs)
+	}
+
+	desc, err = Describe(ctx, conn, "INSERT INTO test_describe (id, name) VALUES (:id, :name)")
+	if err != nil {
+		t.Fatalf("Error Describe: %v", err)
+	}
+	if desc.StatementType != "INSERT" || desc.HasCursor || len(desc.Inputs) != 2 || desc.Inputs[1].Name != "name" {
+		t.Errorf("Incorrect description: %+v", desc)
+	}
+
+	// not executed
+	var n int
+	if err = conn.QueryRow("SELECT COUNT(*) FROM test_describe").Scan(&n); err != nil || n != 0 {
+		t.Fatalf("Statement is executed: %v %v", n, err)
+	}
+
+	if _, err = Describe(ctx, conn, "SELECT no_such_column FROM test_describe"); err == nil {
+		t.Fatalf("Error is not returned")
+	}
+}
+
+func TestLastInsertId(t *testing.T) {
+	temppath := TempFileName("test_last_insert_id_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_identity (id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY, name VARCHAR(10))")
+	conn.Exec("CREATE TABLE test_pk (code INTEGER NOT NULL PRIMARY KEY, name VARCHAR(10))")
+	conn.Exec("CREATE TABLE test_no_key (name VARCHAR(10))")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+	ctx := WithLastInsertId(context.Background())
+
+	for i := int64(1); i <= 2; i++ {
+		res, err := conn.ExecContext(ctx, "INSERT INTO test_identity (name) VALUES (?)", "a")
+		if err != nil {
+			t.Fatalf("Error Exec: %v", err)
+		}
+		if id, _ := res.LastInsertId(); id != i {
+			t.Fatalf("Incorrect LastInsertId: %v", id)
+		}
+		if n, _ := res.RowsAffected(); n != 1 {
+			t.Fatalf("Incorrect RowsAffected: %v", n)
+		}
+	}
+
+	res, err := conn.ExecContext(ctx, "INSERT INTO test_pk (code, name) VALUES (:code, :name)", sql.Named("name", "b"), sql.Named("code", 42))
+	if err != nil {
+		t.Fatalf("Error Exec: %v", err)
+	}
+	if id, _ := res.LastInsertId(); id != 42 {
+		t.Fatalf("Incorrect LastInsertId: %v", id)
+	}
+
+	// MATCHING may update several rows, Firebird 5.0 reports it with a cursor
+	for _, code := range []int{42, 43} {
+		res, err = conn.ExecContext(ctx, "UPDATE OR INSERT INTO test_pk (code, name) VALUES (?, 'e') MATCHING (code);", code)
+		if err != nil {
+			t.Fatalf("Error UPDATE OR INSERT: %v", err)
+		}
+		if id, _ := res.LastInsertId(); id != int64(code) {
+			t.Fatalf("Incorrect LastInsertId of UPDATE OR INSERT: %v", id)
+		}
+	}
+
+	res, err = conn.ExecContext(ctx, "INSERT INTO test_no_key (name) VALUES ('c')")
+	if err != nil {
+		t.Fatalf("Error Exec: %v", err)
+	}
+	if id, _ := res.LastInsertId(); id != -1 {
+		t.Fatalf("Incorrect LastInsertId: %v", id)
+	}
+
+	// not opted in
+	res, err = conn.Exec("INSERT INTO test_identity (name) VALUES ('d')")
+	if err != nil {
+		t.Fatalf("Error Exec: %v", err)
+	}
+	if id, _ := res.LastInsertId(); id != -1 {
+		t.Fatalf("Incorrect LastInsertId: %v", id)
+	}
+}
+
+func TestArray(t *testing.T) {
+	temppath := TempFileName("test_array_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_array (id INTEGER NOT NULL, i INTEGER[2, 0:2], s VARCHAR(5)[2], d NUMERIC(9, 2)[2])")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Exec("INSERT INTO test_array (id, i, s, d) VALUES (1, ?, ?, ?)",
+		[][]int32{{1, 2, 3}, {4, 5, 6}}, []string{"a", "bcd"}, []string{"1.25", "-3"})
+	if err != nil {
+		t.Fatalf("Error Insert: %v", err)
+	}
+	if _, err = conn.Exec("INSERT INTO test_array (id, i) VALUES (2, ?)", []int32{1, 2}); err == nil {
+		t.Fatalf("Dimension mismatch is not detected")
+	}
+
+	var i [][]int32
+	var s []string
+	var d interface{}
+	err = conn.QueryRow("SELECT i, s, d FROM test_array WHERE id = 1").Scan(&i, &s, &d)
+	if err != nil {
+		t.Fatalf("Error Query: %v", err)
+	}
+	if !reflect.DeepEqual(i, [][]int32{{1, 2, 3}, {4, 5, 6}}) {
+		t.Errorf("Incorrect INTEGER array: %v", i)
+	}
+	if !reflect.DeepEqual(s, []string{"a", "bcd"}) {
+		t.Errorf("Incorrect VARCHAR array: %v", s)
+	}
+	if ds, ok := d.([]decimal.Decimal); !ok || len(ds) != 2 || ds[0].String() != "1.25" || ds[1].String() != "-3" {
+		t.Errorf("Incorrect NUMERIC array: %v", d)
+	}
+
+	var null interface{}
+	conn.Exec("INSERT INTO test_array (id) VALUES (3)")
+	if err = conn.QueryRow("SELECT i FROM test_array WHERE id = 3").Scan(&null); err != nil || null != nil {
+		t.Errorf("Incorrect NULL array: %v %v", null, err)
+	}
+}
@@ -0,0 +1,124 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Firebird status codes for transient errors
+const (
+	isc_deadlock        = 335544336
+	isc_lock_conflict   = 335544345
+	isc_update_conflict = 335544451
+	isc_lock_timeout    = 335544510
+	isc_read_conflict   = 335545096
+
+	isc_obsolete_metadata = 335544356
+)
+
+const (
+	maxTxRetries    = 5
+	txRetryBaseWait = 10 * time.Millisecond
+)
+
+// FbError is an error reported by the Firebird server.
+type FbError struct {
+	GDSCodes []int // status codes (isc_* in ibase.h) in the order received
+	SQLCode  int
+	Message  string
+}
+
+func (e *FbError) Error() string {
+	return e.Message
+}
+
+// HasGDSCode reports whether code is in the status vector.
+func (e *FbError) HasGDSCode(code int) bool {
+	for _, c := range e.GDSCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func hasGDSCode(err error, code int) bool {
+	var fbErr *FbError
+	return errors.As(err, &fbErr) && fbErr.HasGDSCode(code)
+}
+
+// IsTransientError reports whether err is a deadlock, lock conflict or
+// update conflict that may succeed if the transaction is run again.
+func IsTransientError(err error) bool {
+	var fbErr *FbError
+	if !errors.As(err, &fbErr) {
+		return false
+	}
+	for _, code := range []int{isc_deadlock, isc_lock_conflict, isc_update_conflict, isc_lock_timeout, isc_read_conflict} {
+		if fbErr.HasGDSCode(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunInTx runs fn in a transaction and commits it.
+// If fn or commit fails with a transient error (see IsTransientError),
+// the transaction is rolled back and fn is run again in a new transaction
+// with exponential backoff, until it succeeds, ctx is done or the retry limit is reached.
+func RunInTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(*sql.Tx) error) (err error) {
+	wait := txRetryBaseWait
+	for i := 0; ; i++ {
+		err = runInTx(ctx, db, opts, fn)
+		if err == nil || !IsTransientError(err) || i >= maxTxRetries {
+			return
+		}
+
+		timer := time.NewTimer(wait + time.Duration(rand.Int63n(int64(wait))))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		wait *= 2
+	}
+}
+
+func runInTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(*sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return
+	}
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,144 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+)
+
+type lastInsertIdKey struct{}
+
+// WithLastInsertId returns a context that makes Exec of INSERT ... VALUES and
+// UPDATE OR INSERT statements without RETURNING return the generated key with LastInsertId.
+// The key is the integer identity column of the table, or the integer primary key
+// of a single column. RETURNING for the key is appended to the statement,
+// so statements prepared before are not affected.
+func WithLastInsertId(ctx context.Context) context.Context {
+	return context.WithValue(ctx, lastInsertIdKey{}, true)
+}
+
+func lastInsertIdFromContext(ctx context.Context) bool {
+	enabled, _ := ctx.Value(lastInsertIdKey{}).(bool)
+	return enabled
+}
+
+// insertRelation returns the table a single row is inserted into, or "" for other statements.
+func insertRelation(tokens []sqlToken) string {
+	var i int
+	switch {
+	case isWord(tokens, "INSERT", "INTO"):
+		i = 2
+	case isWord(tokens, "UPDATE", "OR", "INSERT", "INTO"):
+		i = 4
+	default:
+		return ""
+	}
+	if i >= len(tokens) || (tokens[i].kind != sqlTokenWord && (tokens[i].kind != sqlTokenQuoted || tokens[i].text[0] != '"')) {
+		return ""
+	}
+	if i+1 < len(tokens) && tokens[i+1].text == "." {
+		return ""
+	}
+	depth := 0
+	for j, tok := range tokens[i+1:] {
+		switch {
+		case tok.text == "(":
+			depth++
+		case tok.text == ")":
+			depth--
+		case isWord(tokens[i+1+j:], "RETURNING"):
+			return ""
+		case depth == 0 && isWord(tokens[i+1+j:], "SELECT"):
+			// may insert multiple rows
+			return ""
+		}
+	}
+	return normalizeIdentifier(tokens[i].text)
+}
+
+// trimTerminator removes the trailing ; of the statement to append a clause.
+func trimTerminator(query string) string {
+	tokens := tokenizeSQL(query)
+	if n := len(tokens); n > 0 && tokens[n-1].kind == sqlTokenSymbol && tokens[n-1].text == ";" {
+		return query[:tokens[n-1].pos]
+	}
+	return query
+}
+
+// insertKeyColumn returns the generated key column of the table query inserts into,
+// or "" if there is not such a column.
+func (fc *firebirdsqlConn) insertKeyColumn(ctx context.Context, query string) (key string, err error) {
+	relation := insertRelation(tokenizeSQL(query))
+	if relation == "" {
+		return
+	}
+	key, ok := fc.insertKeys[relation]
+	if ok {
+		return
+	}
+	if fc.wp.protocolVersion >= PROTOCOL_VERSION13 {
+		// identity columns and rdb$identity_type are Firebird 3.0+
+		key, err = fc.queryString(ctx, `
+			SELECT TRIM(rf.rdb$field_name) FROM rdb$relation_fields rf
+			JOIN rdb$fields f ON f.rdb$field_name = rf.rdb$field_source
+			WHERE rf.rdb$relation_name = ? AND rf.rdb$identity_type IS NOT NULL
+			AND f.rdb$field_type IN (7, 8, 16) AND COALESCE(f.rdb$field_scale, 0) = 0`, relation)
+	}
+	if err == nil && key == "" {
+		key, err = fc.queryString(ctx, `
+			SELECT TRIM(s.rdb$field_name) FROM rdb$relation_constraints rc
+			JOIN rdb$index_segments s ON s.rdb$index_name = rc.rdb$index_name
+			JOIN rdb$relation_fields rf ON rf.rdb$relation_name = rc.rdb$relation_name AND rf.rdb$field_name = s.rdb$field_name
+			JOIN rdb$fields f ON f.rdb$field_name = rf.rdb$field_source
+			WHERE rc.rdb$relation_name = ? AND rc.rdb$constraint_type = 'PRIMARY KEY'
+			AND f.rdb$field_type IN (7, 8, 16) AND COALESCE(f.rdb$field_scale, 0) = 0
+			AND (SELECT COUNT(*) FROM rdb$index_segments s2 WHERE s2.rdb$index_name = rc.rdb$index_name) = 1`, relation)
+	}
+	if err != nil {
+		return
+	}
+	if fc.insertKeys == nil {
+		fc.insertKeys = make(map[string]string)
+	}
+	fc.insertKeys[relation] = key
+	return
+}
+
+// queryString returns the first column of the first row of query, or "" if there is no row.
+func (fc *firebirdsqlConn) queryString(ctx context.Context, query string, arg string) (s string, err error) {
+	rows, err := fc.query(ctx, query, []driver.NamedValue{{Ordinal: 1, Value: arg}})
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	dest := make([]driver.Value, 1)
+	err = rows.Next(dest)
+	if err == io.EOF {
+		return "", nil
+	}
+	s, _ = dest[0].(string)
+	return
+}
@@ -0,0 +1,64 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"context"
+	"testing"
+)
+
+func TestInsertRelation(t *testing.T) {
+	for _, tc := range []struct {
+		query    string
+		expected string
+	}{
+		{"INSERT INTO foo (a) VALUES (1)", "FOO"},
+		{"insert into \"Foo\" (a) values ((SELECT MAX(a) FROM bar))", "Foo"},
+		{"INSERT INTO foo DEFAULT VALUES", "FOO"},
+		{"UPDATE OR INSERT INTO foo (id, a) VALUES (1, 2) MATCHING (id)", "FOO"},
+		{"INSERT INTO foo (a) VALUES (1) RETURNING id", ""},
+		{"INSERT INTO foo (a) SELECT a FROM bar", ""},
+		{"INSERT INTO s.foo (a) VALUES (1)", ""},
+		{"UPDATE foo SET a = 1", ""},
+		{"MERGE INTO foo USING bar ON foo.id = bar.id WHEN NOT MATCHED THEN INSERT (a) VALUES (1)", ""},
+	} {
+		if relation := insertRelation(tokenizeSQL(tc.query)); relation != tc.expected {
+			t.Errorf("insertRelation(%q) = %q", tc.query, relation)
+		}
+	}
+
+	for query, expected := range map[string]string{
+		"INSERT INTO foo VALUES (1);":               "INSERT INTO foo VALUES (1)",
+		"INSERT INTO foo VALUES (1) ; -- comment\n": "INSERT INTO foo VALUES (1) ",
+		"INSERT INTO foo VALUES (';')":              "INSERT INTO foo VALUES (';')",
+	} {
+		if trimmed := trimTerminator(query); trimmed != expected {
+			t.Errorf("trimTerminator(%q) = %q", query, trimmed)
+		}
+	}
+
+	if lastInsertIdFromContext(context.Background()) || !lastInsertIdFromContext(WithLastInsertId(context.Background())) {
+		t.Errorf("Incorrect context value")
+	}
+}
@@ -0,0 +1,78 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+const maxPlanBufferLength = 1 << 18
+
+// Plan prepares query without executing it and returns its execution plan,
+// e.g. "PLAN (FOO NATURAL)".
+func Plan(ctx context.Context, db *sql.DB, query string) (string, error) {
+	return queryPlan(ctx, db, query, isc_info_sql_get_plan)
+}
+
+// ExplainPlan prepares query without executing it and returns its
+// detailed execution plan (Firebird 3.0+).
+func ExplainPlan(ctx context.Context, db *sql.DB, query string) (string, error) {
+	return queryPlan(ctx, db, query, isc_info_sql_explain_plan)
+}
+
+func queryPlan(ctx context.Context, db *sql.DB, query string, item byte) (plan string, err error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	err = rawConn(conn, func(fc *firebirdsqlConn) error {
+		stmt, err := newFirebirdsqlStmt(fc, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		plan, err = stmt.plan(item)
+		return err
+	})
+	return
+}
+
+func (stmt *firebirdsqlStmt) plan(item byte) (plan string, err error) {
+	for bufferLength := int32(BUFFER_LEN); bufferLength <= maxPlanBufferLength; bufferLength *= 4 {
+		stmt.wp.opInfoSql(stmt.stmtHandle, []byte{item}, bufferLength)
+		_, _, buf, err := stmt.wp.opResponse()
+		if err != nil {
+			return "", err
+		}
+		plan, truncated, err := parse_plan(buf, item)
+		if !truncated {
+			return plan, err
+		}
+	}
+	return "", errors.New("Plan is too long")
+}
@@ -0,0 +1,161 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CallProcedure executes the stored procedure name with EXECUTE PROCEDURE.
+// Input arguments are given in order or by the parameter names with sql.Named,
+// trailing inputs may be omitted to use their defaults.
+// Outputs are assigned to sql.Out arguments, by the names if named or in order.
+func CallProcedure(ctx context.Context, db *sql.DB, name string, args ...interface{}) (sql.Result, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var hasPackages bool
+	err = rawConn(conn, func(fc *firebirdsqlConn) error {
+		hasPackages = fc.wp.protocolVersion >= PROTOCOL_VERSION13
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	procName := normalizeIdentifier(name)
+	query := `
+		SELECT TRIM(rdb$parameter_name) FROM rdb$procedure_parameters
+		WHERE rdb$procedure_name = ? AND rdb$parameter_type = 0`
+	if hasPackages {
+		// packaged procedures (Firebird 3.0+) are not called by the bare name
+		query += " AND rdb$package_name IS NULL"
+	}
+	rows, err := conn.QueryContext(ctx, query+" ORDER BY rdb$parameter_number", procName)
+	if err != nil {
+		return nil, err
+	}
+	var inputNames []string
+	for rows.Next() {
+		var s string
+		if err = rows.Scan(&s); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		inputNames = append(inputNames, s)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	inputs, outs, err := procedureArgs(inputNames, args)
+	if err != nil {
+		return nil, err
+	}
+	placeholders := ""
+	if len(inputs) > 0 {
+		placeholders = "(" + strings.Repeat(", ?", len(inputs))[2:] + ")"
+	}
+	query = fmt.Sprintf(`EXECUTE PROCEDURE "%s"%s`, strings.Replace(procName, `"`, `""`, -1), placeholders)
+	return conn.ExecContext(ctx, query, append(inputs, outs...)...)
+}
+
+// procedureArgs orders the input arguments by the parameters of the procedure.
+// sql.Out arguments are returned in the given order, the current values of
+// sql.Out with In are the inputs.
+func procedureArgs(inputNames []string, args []interface{}) (inputs []interface{}, outs []interface{}, err error) {
+	inputs = make([]interface{}, len(inputNames))
+	set := make([]bool, len(inputNames))
+	next := 0
+	for i, arg := range args {
+		v := arg
+		name := ""
+		if named, ok := arg.(sql.NamedArg); ok {
+			name, v = named.Name, named.Value
+		}
+		if out, ok := v.(sql.Out); ok {
+			if !out.In {
+				outs = append(outs, arg)
+				continue
+			}
+			// the output of the input parameter is assigned in order
+			outs = append(outs, sql.Out{Dest: out.Dest})
+			dv := reflect.ValueOf(out.Dest)
+			if dv.Kind() != reflect.Ptr || dv.IsNil() {
+				return nil, nil, errors.New(fmt.Sprintf("Argument %d: sql.Out destination is not a pointer", i+1))
+			}
+			v = dv.Elem().Interface()
+		}
+
+		j := next
+		if name != "" {
+			j = -1
+			for k, inputName := range inputNames {
+				if strings.EqualFold(inputName, name) {
+					j = k
+				}
+			}
+			if j < 0 {
+				return nil, nil, errors.New(fmt.Sprintf("No input parameter %s", name))
+			}
+		} else {
+			next++
+		}
+		if j >= len(inputNames) {
+			return nil, nil, errors.New(fmt.Sprintf("Expected at most %d input arguments", len(inputNames)))
+		}
+		if set[j] {
+			return nil, nil, errors.New(fmt.Sprintf("Input parameter %s is given twice", inputNames[j]))
+		}
+		inputs[j] = v
+		set[j] = true
+	}
+
+	n := len(set)
+	for n > 0 && !set[n-1] {
+		n--
+	}
+	for j := 0; j < n; j++ {
+		if !set[j] {
+			return nil, nil, errors.New(fmt.Sprintf("Missing input parameter %s", inputNames[j]))
+		}
+	}
+	return inputs[:n], outs, nil
+}
+
+// normalizeIdentifier returns the name stored in the system tables.
+func normalizeIdentifier(name string) string {
+	if len(name) >= 2 && strings.HasPrefix(name, `"`) && strings.HasSuffix(name, `"`) {
+		return strings.Replace(name[1:len(name)-1], `""`, `"`, -1)
+	}
+	return strings.ToUpper(name)
+}
@@ -23,15 +23,97 @@ CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
 
 package firebirdsql
 
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+)
+
+// Result is a driver.Result with the number of records processed by each operation.
+// Use Exec to get it, since sql.Result hides the driver result.
+type Result interface {
+	driver.Result
+	SelectCount() int64
+	InsertCount() int64
+	UpdateCount() int64
+	DeleteCount() int64
+}
+
 type firebirdsqlResult struct {
 	affectedRows int64
+	selectCount  int64
+	insertCount  int64
+	updateCount  int64
+	deleteCount  int64
+	lastInsertId sql.NullInt64
+}
+
+// newFirebirdsqlResult makes a result from the reply of isc_info_sql_records.
+func newFirebirdsqlResult(stmtType int32, buf []byte) (res *firebirdsqlResult, err error) {
+	res = new(firebirdsqlResult)
+	items, err := parseInfoItems(buf)
+	if err != nil {
+		return
+	}
+	records, err := parseInfoItems(items[isc_info_sql_records])
+	if err != nil {
+		return
+	}
+	res.selectCount = bytes_to_portable_int(records[isc_info_req_select_count])
+	res.insertCount = bytes_to_portable_int(records[isc_info_req_insert_count])
+	res.updateCount = bytes_to_portable_int(records[isc_info_req_update_count])
+	res.deleteCount = bytes_to_portable_int(records[isc_info_req_delete_count])
+
+	if stmtType == isc_info_sql_stmt_select {
+		res.affectedRows = res.selectCount
+	} else {
+		res.affectedRows = res.insertCount + res.updateCount + res.deleteCount
+	}
+	return
 }
 
+// LastInsertId returns the generated key of the row inserted with WithLastInsertId, or -1.
 func (res *firebirdsqlResult) LastInsertId() (int64, error) {
-	// Firebird does not have default ID.
-	return -1, nil
+	if !res.lastInsertId.Valid {
+		// Firebird does not have default ID.
+		return -1, nil
+	}
+	return res.lastInsertId.Int64, nil
 }
 
 func (res *firebirdsqlResult) RowsAffected() (int64, error) {
 	return res.affectedRows, nil
 }
+
+func (res *firebirdsqlResult) SelectCount() int64 {
+	return res.selectCount
+}
+
+func (res *firebirdsqlResult) InsertCount() int64 {
+	return res.insertCount
+}
+
+func (res *firebirdsqlResult) UpdateCount() int64 {
+	return res.updateCount
+}
+
+func (res *firebirdsqlResult) DeleteCount() int64 {
+	return res.deleteCount
+}
+
+// Exec executes query on conn like conn.ExecContext and returns the record counts by operation.
+func Exec(ctx context.Context, conn *sql.Conn, query string, args ...interface{}) (result Result, err error) {
+	namedargs, err := interfacesToNamedValues(args)
+	if err != nil {
+		return
+	}
+	err = rawConn(conn, func(fc *firebirdsqlConn) error {
+		res, err := fc.exec(ctx, query, namedargs)
+		if err != nil {
+			return err
+		}
+		result = res.(Result)
+		return nil
+	})
+	return
+}
@@ -0,0 +1,53 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"testing"
+)
+
+func TestRecordCounts(t *testing.T) {
+	buf := []byte{
+		isc_info_sql_records, 29, 0,
+		isc_info_req_update_count, 4, 0, 2, 0, 0, 0,
+		isc_info_req_delete_count, 4, 0, 0, 0, 0, 0,
+		isc_info_req_select_count, 4, 0, 5, 0, 0, 0,
+		isc_info_req_insert_count, 4, 0, 3, 0, 0, 0,
+		isc_info_end,
+		isc_info_end,
+	}
+	res, err := newFirebirdsqlResult(isc_info_sql_stmt_exec_procedure, buf)
+	if err != nil {
+		t.Fatalf("Error newFirebirdsqlResult: %v", err)
+	}
+	n, _ := res.RowsAffected()
+	if n != 5 || res.SelectCount() != 5 || res.InsertCount() != 3 || res.UpdateCount() != 2 || res.DeleteCount() != 0 {
+		t.Fatalf("Incorrect record counts: %+v", res)
+	}
+
+	res, err = newFirebirdsqlResult(isc_info_sql_stmt_select, buf)
+	if n, _ = res.RowsAffected(); err != nil || n != 5 {
+		t.Fatalf("Incorrect select count: %v %v", n, err)
+	}
+}
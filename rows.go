@@ -24,49 +24,81 @@ CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
 package firebirdsql
 
 import (
-	"bytes"
 	"container/list"
+	"context"
 	"database/sql/driver"
 	"io"
 	"reflect"
-	"strings"
 )
 
+const (
+	defaultFetchSize   = 400
+	adaptiveFetchStart = 10        // rows of the first fetch in adaptive mode
+	adaptiveFetchBytes = 64 * 1024 // upper limit of a fetch in adaptive mode
+)
+
+type fetchSizeKey struct{}
+
+// WithFetchSize returns a context that makes queries fetch n rows per round trip.
+// 0 means adaptive, starting with a few rows and growing up to 64KB per fetch.
+func WithFetchSize(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, fetchSizeKey{}, n)
+}
+
+func fetchSizeFromContext(ctx context.Context) (int, bool) {
+	n, ok := ctx.Value(fetchSizeKey{}).(int)
+	return n, ok && n >= 0
+}
+
+type cursorNameKey struct{}
+
+// WithCursorName returns a context that makes a query open the cursor with name.
+// Rows of SELECT ... FOR UPDATE are fetched one by one, so that
+// UPDATE/DELETE ... WHERE CURRENT OF name in the same transaction applies to the row just read.
+func WithCursorName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, cursorNameKey{}, name)
+}
+
+func cursorNameFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(cursorNameKey{}).(string)
+	return name, ok && name != ""
+}
+
 type firebirdsqlRows struct {
 	stmt            *firebirdsqlStmt
 	currentChunkRow *list.Element
+	firstChunk      *list.List // fetched with the execution
 	moreData        bool
 	result          []driver.Value
+	releaseStmt     bool // the statement is not prepared by the user
+	fetchSize       int  // 0 means adaptive
+	lastFetchSize   int
 }
 
-func newFirebirdsqlRows(stmt *firebirdsqlStmt, result []driver.Value) *firebirdsqlRows {
+func newFirebirdsqlRows(stmt *firebirdsqlStmt, result []driver.Value, fetchSize int) *firebirdsqlRows {
 	rows := new(firebirdsqlRows)
 	rows.stmt = stmt
 	rows.result = result
-	if stmt.stmtType == isc_info_sql_stmt_select {
+	rows.fetchSize = fetchSize
+	if stmt.hasCursor() {
 		rows.moreData = true
 	}
 	return rows
 }
 
 func (rows *firebirdsqlRows) Columns() []string {
-	columns := make([]string, len(rows.stmt.xsqlda))
-	for i, x := range rows.stmt.xsqlda {
-		columns[i] = x.aliasname
-		if rows.stmt.tx.fc.columnNameToLower {
-			columns[i] = strings.ToLower(columns[i])
-		}
-	}
-	return columns
+	return rows.stmt.columnNames()
 }
 
 func (rows *firebirdsqlRows) Close() (er error) {
-	rows.stmt.Close()
-	return
+	if rows.releaseStmt {
+		return rows.stmt.tx.fc.releaseStmt(rows.stmt)
+	}
+	return rows.stmt.closeCursor()
 }
 
 func (rows *firebirdsqlRows) Next(dest []driver.Value) (err error) {
-	if rows.stmt.stmtType == isc_info_sql_stmt_exec_procedure {
+	if rows.stmt.isSingleton() {
 		if rows.result != nil {
 			for i, v := range rows.result {
 				dest[i] = v
@@ -81,12 +113,15 @@ func (rows *firebirdsqlRows) Next(dest []driver.Value) (err error) {
 
 	if rows.currentChunkRow != nil {
 		rows.currentChunkRow = rows.currentChunkRow.Next()
+	} else if rows.firstChunk != nil {
+		rows.currentChunkRow = rows.firstChunk.Front()
+		rows.firstChunk = nil
 	}
 
 	if rows.currentChunkRow == nil && rows.moreData == true {
 		// Get one chunk
 		var chunk *list.List
-		rows.stmt.wp.opFetch(rows.stmt.stmtHandle, rows.stmt.blr)
+		rows.stmt.wp.opFetch(rows.stmt.stmtHandle, rows.stmt.blr, int32(rows.nextFetchSize()))
 		chunk, rows.moreData, err = rows.stmt.wp.opFetchResponse(rows.stmt.stmtHandle, rows.stmt.tx.transHandle, rows.stmt.xsqlda)
 
 		if err == nil {
@@ -101,23 +136,27 @@ func (rows *firebirdsqlRows) Next(dest []driver.Value) (err error) {
 		return
 	}
 	row, _ := rows.currentChunkRow.Value.([]driver.Value)
-	for i, v := range row {
-		if rows.stmt.xsqlda[i].sqltype == SQL_TYPE_BLOB && v != nil {
-			blobId := v.([]byte)
-			var blob []byte
-			blob, err = rows.stmt.wp.getBlobSegments(blobId, rows.stmt.tx.transHandle)
-			if rows.stmt.xsqlda[i].sqlsubtype == 1 {
-				dest[i] = bytes.NewBuffer(blob).String()
-			} else {
-				dest[i] = blob
-			}
+	return rows.stmt.readRow(row, dest)
+}
 
-		} else {
-			dest[i] = v
-		}
+// nextFetchSize returns the number of rows to fetch.
+// Adaptive fetch doubles from adaptiveFetchStart rows up to the rows fitting in adaptiveFetchBytes.
+func (rows *firebirdsqlRows) nextFetchSize() int {
+	if rows.fetchSize > 0 {
+		return rows.fetchSize
 	}
-
-	return
+	n := adaptiveFetchStart
+	if rows.lastFetchSize > 0 {
+		n = rows.lastFetchSize * 2
+	}
+	if max := adaptiveFetchBytes / calcRowLength(rows.stmt.xsqlda); n > max {
+		n = max
+	}
+	if n < 1 {
+		n = 1
+	}
+	rows.lastFetchSize = n
+	return n
 }
 
 func (rows *firebirdsqlRows) ColumnTypeDatabaseTypeName(index int) string {
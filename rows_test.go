@@ -0,0 +1,49 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"testing"
+)
+
+func TestNextFetchSize(t *testing.T) {
+	stmt := &firebirdsqlStmt{xsqlda: []xSQLVAR{{sqltype: SQL_TYPE_LONG}}}
+	rows := newFirebirdsqlRows(stmt, nil, 100)
+	if n := rows.nextFetchSize(); n != 100 {
+		t.Fatalf("Incorrect fetch size: %v", n)
+	}
+
+	rows = newFirebirdsqlRows(stmt, nil, 0)
+	for _, expected := range []int{10, 20, 40, 80} {
+		if n := rows.nextFetchSize(); n != expected {
+			t.Fatalf("Incorrect adaptive fetch size: %v != %v", n, expected)
+		}
+	}
+
+	stmt = &firebirdsqlStmt{xsqlda: []xSQLVAR{{sqltype: SQL_TYPE_VARYING, sqllen: 32000}, {sqltype: SQL_TYPE_VARYING, sqllen: 32000}}}
+	rows = newFirebirdsqlRows(stmt, nil, 0)
+	if n := rows.nextFetchSize(); n != 1 {
+		t.Fatalf("Incorrect adaptive fetch size for wide rows: %v", n)
+	}
+}
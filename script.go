@@ -0,0 +1,387 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// ScriptError is an error of a statement in a script given to ExecScript.
+type ScriptError struct {
+	Line      int // location of the statement from 1
+	Column    int
+	Statement string
+	Err       error
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+type scriptStatement struct {
+	sql    string
+	line   int
+	column int
+}
+
+// isql commands which only change the output of isql
+var isqlSetCommands = []string{
+	"NAMES", "SQL", "ECHO", "BAIL", "LIST", "HEADING", "COUNT", "PLAN", "PLANONLY", "STATS", "WARNINGS", "WNG",
+	"BLOBDISPLAY", "BLOB", "WIDTH", "ROWCOUNT", "MAXROWS", "EXPLAIN", "PER_TABLE_STATS", "KEEP_TRAN_PARAMS",
+	"AUTOTERM", "EXEC_PATH_DISPLAY",
+}
+
+// ExecScript executes an isql script in order.
+// Statements are separated by the terminator (changed with SET TERM), and
+// PSQL bodies of CREATE PROCEDURE/TRIGGER/FUNCTION/PACKAGE and EXECUTE BLOCK
+// are kept together without SET TERM too.
+// COMMIT, ROLLBACK and SET AUTODDL work as in isql: while AUTODDL is ON (the default)
+// DDL statements are committed in a transaction of their own, so a later ROLLBACK
+// still undoes the other statements. SET TRANSACTION commits the current transaction
+// and starts one with its options, which are kept for the following transactions
+// as with SET KEEP_TRAN_PARAMS ON. Other isql SET commands are ignored.
+// Statements other than SELECT are executed without preparing them.
+// The script stops at the first error, which is returned as *ScriptError
+// after the current transaction is rolled back.
+func ExecScript(ctx context.Context, db *sql.DB, r io.Reader) (err error) {
+	script, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	txCtx := ctx // with the options of SET TRANSACTION
+	tx, err := conn.BeginTx(txCtx, nil)
+	if err != nil {
+		return
+	}
+	autoDDL := true
+	restart := func(commit bool) error {
+		var err error
+		if commit {
+			err = tx.Commit()
+		} else {
+			err = tx.Rollback()
+		}
+		if err != nil {
+			return err
+		}
+		tx, err = conn.BeginTx(txCtx, nil)
+		return err
+	}
+
+	for _, stmt := range splitScript(string(script)) {
+		tokens := tokenizeSQL(stmt.sql)
+		switch {
+		case isTransactionEnd(tokens, "COMMIT"):
+			err = restart(true)
+		case isTransactionEnd(tokens, "ROLLBACK"):
+			err = restart(false)
+		case isWord(tokens, "EXIT"):
+			return tx.Commit()
+		case isWord(tokens, "QUIT"):
+			return tx.Rollback()
+		case isWord(tokens, "SET", "AUTODDL"):
+			if len(tokens) > 2 {
+				autoDDL = !strings.EqualFold(tokens[2].text, "OFF")
+			} else {
+				autoDDL = !autoDDL
+			}
+		case isISQLSetCommand(tokens):
+		case isWord(tokens, "CONNECT"), isWord(tokens, "CREATE", "DATABASE"), isWord(tokens, "CREATE", "SCHEMA"):
+			err = errors.New(fmt.Sprintf("%s is not supported in scripts", strings.ToUpper(tokens[0].text)))
+		case isWord(tokens, "SET", "TRANSACTION"):
+			var opts TransactionOptions
+			if opts, err = parseSetTransaction(tokens); err == nil {
+				txCtx = WithTransactionOptions(ctx, opts)
+				err = restart(true)
+			}
+		case isWord(tokens, "SELECT"), isWord(tokens, "WITH"):
+			// rows are fetched and discarded
+			_, err = tx.ExecContext(ctx, stmt.sql)
+		default:
+			err = rawConn(conn, func(fc *firebirdsqlConn) error {
+				if !isDDL(tokens) {
+					// in tx, which is the current transaction of the connection
+					return fc.execImmediate(stmt.sql)
+				}
+				// cached statements may lock the objects to be changed
+				fc.clearStmtCache()
+				if autoDDL {
+					return fc.execImmediateCommitted(stmt.sql)
+				}
+				return fc.execImmediate(stmt.sql)
+			})
+		}
+		if err != nil {
+			tx.Rollback()
+			return &ScriptError{Line: stmt.line, Column: stmt.column, Statement: stmt.sql, Err: err}
+		}
+	}
+	return tx.Commit()
+}
+
+// parseSetTransaction returns the options of SET TRANSACTION, whose defaults are
+// READ WRITE, WAIT and ISOLATION LEVEL SNAPSHOT.
+func parseSetTransaction(tokens []sqlToken) (opts TransactionOptions, err error) {
+	opts.Isolation = ISOLATION_LEVEL_REPEATABLE_READ
+	i := 2
+	word := func(keywords ...string) bool {
+		if isWord(tokens[i:], keywords...) {
+			i += len(keywords)
+			return true
+		}
+		return false
+	}
+	number := func() (int64, error) {
+		if i >= len(tokens) {
+			return 0, errors.New("Number is expected at the end of SET TRANSACTION")
+		}
+		i++
+		n, err := strconv.ParseInt(tokens[i-1].text, 10, 64)
+		if err != nil || n < 0 {
+			return 0, errors.New(fmt.Sprintf("Invalid number %s in SET TRANSACTION", tokens[i-1].text))
+		}
+		return n, nil
+	}
+
+	for i < len(tokens) && err == nil {
+		var n int64
+		switch {
+		case word("READ", "ONLY"):
+			opts.ReadOnly = true
+		case word("READ", "WRITE"):
+			opts.ReadOnly = false
+		case word("WAIT"):
+			opts.NoWait = false
+		case word("NO", "WAIT"):
+			opts.NoWait = true
+		case word("LOCK", "TIMEOUT"):
+			n, err = number()
+			opts.LockTimeout = int(n)
+		case word("ISOLATION", "LEVEL"):
+		case word("SNAPSHOT", "TABLE", "STABILITY"), word("SNAPSHOT", "TABLE"):
+			opts.Isolation = ISOLATION_LEVEL_SERIALIZABLE
+		case word("SNAPSHOT", "AT", "NUMBER"):
+			opts.Isolation = ISOLATION_LEVEL_REPEATABLE_READ
+			opts.AtSnapshotNumber, err = number()
+		case word("SNAPSHOT"):
+			opts.Isolation = ISOLATION_LEVEL_REPEATABLE_READ
+		case word("READ", "COMMITTED"), word("READ", "UNCOMMITTED"):
+			switch {
+			case word("RECORD_VERSION"):
+				opts.Isolation = ISOLATION_LEVEL_READ_COMMITED
+			case word("READ", "CONSISTENCY"):
+				opts.Isolation = ISOLATION_LEVEL_READ_CONSISTENCY
+			default: // NO RECORD_VERSION
+				word("NO", "RECORD_VERSION")
+				opts.Isolation = ISOLATION_LEVEL_READ_COMMITED_LEGACY
+			}
+		case word("NO", "AUTO", "UNDO"):
+			opts.NoAutoUndo = true
+		case word("IGNORE", "LIMBO"):
+			opts.IgnoreLimbo = true
+		case word("AUTO", "COMMIT"):
+			opts.AutoCommit = true
+		case word("RESERVING"):
+			var r []TableReservation
+			r, i, err = parseReservations(tokens, i)
+			opts.Reservations = append(opts.Reservations, r...)
+		default:
+			err = errors.New(fmt.Sprintf("SET TRANSACTION option %s is not supported", tokens[i].text))
+		}
+	}
+	return
+}
+
+// parseReservations parses the table list of RESERVING from tokens[i]:
+// "table [, table ...] [FOR [SHARED | PROTECTED] {READ | WRITE}] [, ...]".
+func parseReservations(tokens []sqlToken, i int) (reservations []TableReservation, next int, err error) {
+	var tables []string
+	for i < len(tokens) {
+		if tokens[i].kind != sqlTokenWord && (tokens[i].kind != sqlTokenQuoted || tokens[i].text[0] != '"') {
+			return nil, i, errors.New(fmt.Sprintf("Table name is expected in RESERVING, not %s", tokens[i].text))
+		}
+		tables = append(tables, normalizeIdentifier(tokens[i].text))
+		i++
+		if isWord(tokens[i:], "FOR") {
+			r := TableReservation{Mode: RESERVATION_SHARED}
+			i++
+			if isWord(tokens[i:], "PROTECTED") {
+				r.Mode = RESERVATION_PROTECTED
+				i++
+			} else if isWord(tokens[i:], "SHARED") {
+				i++
+			}
+			switch {
+			case isWord(tokens[i:], "WRITE"):
+				r.Write = true
+			case isWord(tokens[i:], "READ"):
+			default:
+				return nil, i, errors.New("READ or WRITE is expected in RESERVING")
+			}
+			i++
+			for _, table := range tables {
+				r.Table = table
+				reservations = append(reservations, r)
+			}
+			tables = nil
+		}
+		if i >= len(tokens) || tokens[i].text != "," {
+			break
+		}
+		i++
+	}
+	for _, table := range tables {
+		reservations = append(reservations, TableReservation{Table: table, Mode: RESERVATION_SHARED})
+	}
+	return reservations, i, nil
+}
+
+// isTransactionEnd reports whether the statement is "COMMIT [WORK]" or "ROLLBACK [WORK]".
+func isTransactionEnd(tokens []sqlToken, keyword string) bool {
+	return (len(tokens) == 1 && isWord(tokens, keyword)) || (len(tokens) == 2 && isWord(tokens, keyword, "WORK"))
+}
+
+func isISQLSetCommand(tokens []sqlToken) bool {
+	if !isWord(tokens, "SET") || len(tokens) < 2 {
+		return false
+	}
+	if isWord(tokens, "SET", "TIME") {
+		return !isWord(tokens, "SET", "TIME", "ZONE")
+	}
+	for _, command := range isqlSetCommands {
+		if isWord(tokens[1:], command) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitScript splits a script into statements.
+func splitScript(script string) (stmts []scriptStatement) {
+	term := ";"
+	start := 0
+	var blocks []bool // BEGIN or CASE ... END, true for BEGIN
+	bodyEnded := false
+
+	emit := func(end int) {
+		text := script[start:end]
+		tokens := tokenizeSQL(text)
+		if len(tokens) == 0 {
+			return
+		}
+		if isWord(tokens, "SET", "TERM") && len(tokens) > 2 {
+			term = strings.TrimSpace(text[tokens[2].pos:])
+			return
+		}
+		pos := start + tokens[0].pos
+		line := strings.Count(script[:pos], "\n") + 1
+		column := pos - strings.LastIndex(script[:pos], "\n")
+		stmts = append(stmts, scriptStatement{
+			sql:    strings.TrimSpace(text[tokens[0].pos:]),
+			line:   line,
+			column: column,
+		})
+	}
+
+	i := 0
+	for i < len(script) {
+		c := script[i]
+		switch {
+		case strings.HasPrefix(script[i:], "--"):
+			if end := strings.IndexByte(script[i:], '\n'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(script)
+			}
+		case strings.HasPrefix(script[i:], "/*"):
+			if end := strings.Index(script[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(script)
+			}
+		case c == '\'' || c == '"':
+			i = scanQuoted(script, i, c)
+		case (c == 'q' || c == 'Q') && i+1 < len(script) && script[i+1] == '\'':
+			i = scanAlternativeQuoted(script, i)
+		case strings.HasPrefix(script[i:], term):
+			if term == ";" && (len(blocks) > 0 || (!bodyEnded && hasPSQLBody(tokenizeSQL(script[start:i])))) {
+				i++
+				continue
+			}
+			emit(i)
+			i += len(term)
+			start = i
+			blocks = blocks[:0]
+			bodyEnded = false
+		case isIdentifierChar(c):
+			end := i
+			for end < len(script) && isIdentifierChar(script[end]) {
+				end++
+			}
+			word := strings.ToUpper(script[i:end])
+			switch {
+			case word == "BEGIN" || word == "CASE":
+				blocks = append(blocks, word == "BEGIN")
+			case word == "END" && len(blocks) > 0:
+				bodyEnded = blocks[len(blocks)-1] && len(blocks) == 1
+				blocks = blocks[:len(blocks)-1]
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	emit(len(script))
+	return
+}
+
+// hasPSQLBody reports whether the statement has a PSQL body which may contain ';'.
+func hasPSQLBody(tokens []sqlToken) bool {
+	if !isPSQLDefinition(tokens) && !isWord(tokens, "EXECUTE", "BLOCK") {
+		return false
+	}
+	for i := range tokens {
+		if isWord(tokens[i:], "AS") {
+			return true
+		}
+	}
+	return false
+}
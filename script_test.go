@@ -0,0 +1,191 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitScript(t *testing.T) {
+	script := `-- schema
+CREATE TABLE foo (a INTEGER, b VARCHAR(10) DEFAULT ';');
+/* comment; */ INSERT INTO foo (a, b) VALUES (1, 'x;y');
+
+SET TERM ^ ;
+CREATE PROCEDURE p1 AS
+BEGIN
+  INSERT INTO foo (a) VALUES (2);
+END^
+SET TERM ; ^
+CREATE PROCEDURE p2 RETURNS (r INTEGER) AS
+DECLARE VARIABLE v INTEGER = 1;
+BEGIN
+  r = CASE WHEN v = 1 THEN 1 ELSE 0 END;
+  IF (r = 1) THEN
+  BEGIN
+    SUSPEND;
+  END
+END;
+ALTER TRIGGER t INACTIVE;
+EXECUTE BLOCK AS BEGIN END;
+COMMIT`
+	expected := []scriptStatement{
+		{"CREATE TABLE foo (a INTEGER, b VARCHAR(10) DEFAULT ';')", 2, 1},
+		{"INSERT INTO foo (a, b) VALUES (1, 'x;y')", 3, 16},
+		{"CREATE PROCEDURE p1 AS\nBEGIN\n  INSERT INTO foo (a) VALUES (2);\nEND", 6, 1},
+		{"CREATE PROCEDURE p2 RETURNS (r INTEGER) AS\nDECLARE VARIABLE v INTEGER = 1;\nBEGIN\n  r = CASE WHEN v = 1 THEN 1 ELSE 0 END;\n  IF (r = 1) THEN\n  BEGIN\n    SUSPEND;\n  END\nEND", 11, 1},
+		{"ALTER TRIGGER t INACTIVE", 20, 1},
+		{"EXECUTE BLOCK AS BEGIN END", 21, 1},
+		{"COMMIT", 22, 1},
+	}
+	stmts := splitScript(script)
+	if len(stmts) != len(expected) {
+		t.Fatalf("Incorrect statements: %q", stmts)
+	}
+	for i := range expected {
+		if stmts[i] != expected[i] {
+			t.Errorf("Incorrect statement %d: %q, expected %q", i, stmts[i], expected[i])
+		}
+	}
+}
+
+func TestParseSetTransaction(t *testing.T) {
+	opts, err := parseSetTransaction(tokenizeSQL("SET TRANSACTION"))
+	if err != nil || opts.Isolation != ISOLATION_LEVEL_REPEATABLE_READ || opts.ReadOnly || opts.NoWait {
+		t.Fatalf("Incorrect default options: %v %v", opts, err)
+	}
+
+	opts, err = parseSetTransaction(tokenizeSQL(
+		"SET TRANSACTION READ ONLY NO WAIT ISOLATION LEVEL READ COMMITTED RECORD_VERSION LOCK TIMEOUT 5 NO AUTO UNDO"))
+	if err != nil || opts.Isolation != ISOLATION_LEVEL_READ_COMMITED || !opts.ReadOnly || !opts.NoWait ||
+		opts.LockTimeout != 5 || !opts.NoAutoUndo {
+		t.Fatalf("Incorrect options: %v %v", opts, err)
+	}
+
+	for query, isolation := range map[string]int{
+		"SET TRANSACTION READ COMMITTED":                   ISOLATION_LEVEL_READ_COMMITED_LEGACY,
+		"SET TRANSACTION READ COMMITTED NO RECORD_VERSION": ISOLATION_LEVEL_READ_COMMITED_LEGACY,
+		"SET TRANSACTION READ COMMITTED READ CONSISTENCY":  ISOLATION_LEVEL_READ_CONSISTENCY,
+		"SET TRANSACTION SNAPSHOT TABLE STABILITY":         ISOLATION_LEVEL_SERIALIZABLE,
+		"SET TRANSACTION SNAPSHOT AT NUMBER 10":            ISOLATION_LEVEL_REPEATABLE_READ,
+	} {
+		if opts, err = parseSetTransaction(tokenizeSQL(query)); err != nil || opts.Isolation != isolation {
+			t.Errorf("Incorrect isolation of %q: %v %v", query, opts.Isolation, err)
+		}
+	}
+	if opts, _ = parseSetTransaction(tokenizeSQL("SET TRANSACTION SNAPSHOT AT NUMBER 10")); opts.AtSnapshotNumber != 10 {
+		t.Errorf("Incorrect snapshot number: %v", opts.AtSnapshotNumber)
+	}
+
+	opts, err = parseSetTransaction(tokenizeSQL(`SET TRANSACTION RESERVING a, "b" FOR PROTECTED WRITE, c`))
+	expected := []TableReservation{
+		{Table: "A", Write: true, Mode: RESERVATION_PROTECTED},
+		{Table: "b", Write: true, Mode: RESERVATION_PROTECTED},
+		{Table: "C", Mode: RESERVATION_SHARED},
+	}
+	if err != nil || len(opts.Reservations) != len(expected) {
+		t.Fatalf("Incorrect reservations: %v %v", opts.Reservations, err)
+	}
+	for i, r := range expected {
+		if opts.Reservations[i] != r {
+			t.Errorf("Incorrect reservation %d: %v", i, opts.Reservations[i])
+		}
+	}
+
+	if _, err = parseSetTransaction(tokenizeSQL("SET TRANSACTION RESTART REQUESTS")); err == nil {
+		t.Errorf("Unsupported option is not rejected")
+	}
+}
+
+func TestExecScript(t *testing.T) {
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+TempFileName("test_exec_script_"))
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+
+	script := `SET SQL DIALECT 3;
+SET AUTODDL ON;
+CREATE TABLE test_script (a INTEGER NOT NULL PRIMARY KEY);
+SET TERM ^ ;
+CREATE PROCEDURE test_script_insert (n INTEGER) AS
+BEGIN
+  INSERT INTO test_script (a) VALUES (:n);
+END^
+SET TERM ; ^
+EXECUTE PROCEDURE test_script_insert 1;
+INSERT INTO test_script (a) VALUES (2);
+ROLLBACK;
+EXECUTE PROCEDURE test_script_insert 3;
+COMMIT;
+`
+	ctx := context.Background()
+	if err = ExecScript(ctx, conn, strings.NewReader(script)); err != nil {
+		t.Fatalf("Error ExecScript: %v", err)
+	}
+	var n int
+	conn.QueryRow("SELECT sum(a) FROM test_script").Scan(&n)
+	if n != 3 {
+		t.Fatalf("Incorrect result: %v", n)
+	}
+
+	time.Sleep(1 * time.Second)
+
+	err = ExecScript(ctx, conn, strings.NewReader("INSERT INTO test_script (a) VALUES (4);\n\n  INSERT INTO test_script (a) VALUES (3);\n"))
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) {
+		t.Fatalf("Error is not ScriptError: %v", err)
+	}
+	if scriptErr.Line != 3 || scriptErr.Column != 3 || !hasGDSCode(err, 335544665) {
+		t.Fatalf("Incorrect ScriptError: %v", scriptErr)
+	}
+	conn.QueryRow("SELECT count(*) FROM test_script").Scan(&n)
+	if n != 1 {
+		t.Fatalf("Failed script is not rolled back: %v", n)
+	}
+
+	// DDL is committed by itself, ROLLBACK undoes the INSERT before it
+	script = `INSERT INTO test_script (a) VALUES (5);
+CREATE TABLE test_script2 (b INTEGER);
+ROLLBACK;
+INSERT INTO test_script2 (b) VALUES (1);
+`
+	if err = ExecScript(ctx, conn, strings.NewReader(script)); err != nil {
+		t.Fatalf("Error ExecScript: %v", err)
+	}
+	conn.QueryRow("SELECT count(*) FROM test_script").Scan(&n)
+	if n != 1 {
+		t.Fatalf("INSERT before DDL is not rolled back: %v", n)
+	}
+
+	err = ExecScript(ctx, conn, strings.NewReader("SET TRANSACTION READ ONLY;\nINSERT INTO test_script (a) VALUES (6);\n"))
+	if !errors.As(err, &scriptErr) || scriptErr.Line != 2 {
+		t.Fatalf("INSERT in READ ONLY transaction is not rejected: %v", err)
+	}
+}
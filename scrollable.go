@@ -0,0 +1,259 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// ScrollableRows is the result of a query on a scrollable cursor (Firebird 5.0+).
+// Unlike sql.Rows, the cursor can be moved in any direction.
+// The cursor is closed by Close or when the transaction ends.
+type ScrollableRows struct {
+	conn *sql.Conn
+	stmt *firebirdsqlStmt
+	row  []driver.Value
+	err  error
+}
+
+// QueryScrollable executes a query on conn and returns rows on a scrollable cursor
+// positioned before the first row.
+func QueryScrollable(ctx context.Context, conn *sql.Conn, query string, args ...interface{}) (*ScrollableRows, error) {
+	namedargs, err := interfacesToNamedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	rows := &ScrollableRows{conn: conn}
+	err = rawConn(conn, func(fc *firebirdsqlConn) error {
+		if fc.wp.protocolVersion < PROTOCOL_VERSION18 {
+			return errors.New("Scrollable cursor requires Firebird 5.0 or higher")
+		}
+		stmt, err := newFirebirdsqlStmt(fc, query)
+		if err != nil {
+			return err
+		}
+		if !stmt.hasCursor() {
+			stmt.Close()
+			return errors.New("Scrollable cursor needs a SELECT statement")
+		}
+		blr, values, err := stmt.bindParams(namedargs)
+		if err == nil {
+			err = stmt.execute(blr, values, cursor_type_scrollable)
+		}
+		if err != nil {
+			stmt.Close()
+			return err
+		}
+		rows.stmt = stmt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (rows *ScrollableRows) fetch(fetchOp int32, pos int) bool {
+	rows.row = nil
+	if rows.stmt == nil {
+		rows.err = errors.New("Rows are closed")
+		return false
+	}
+	rows.err = rawConn(rows.conn, func(fc *firebirdsqlConn) error {
+		stmt := rows.stmt
+		stmt.wp.opFetchScroll(stmt.stmtHandle, stmt.blr, fetchOp, int32(pos))
+		chunk, _, err := stmt.wp.opFetchResponse(stmt.stmtHandle, stmt.tx.transHandle, stmt.xsqlda)
+		if err != nil || chunk.Len() == 0 {
+			return err
+		}
+		row := make([]driver.Value, len(stmt.xsqlda))
+		err = stmt.readRow(chunk.Front().Value.([]driver.Value), row)
+		if err == nil {
+			rows.row = row
+		}
+		return err
+	})
+	return rows.row != nil
+}
+
+// Next moves to the next row and reports whether there is a row.
+func (rows *ScrollableRows) Next() bool {
+	return rows.fetch(fetch_next, 0)
+}
+
+// Prior moves to the previous row and reports whether there is a row.
+func (rows *ScrollableRows) Prior() bool {
+	return rows.fetch(fetch_prior, 0)
+}
+
+// First moves to the first row and reports whether there is a row.
+func (rows *ScrollableRows) First() bool {
+	return rows.fetch(fetch_first, 0)
+}
+
+// Last moves to the last row and reports whether there is a row.
+func (rows *ScrollableRows) Last() bool {
+	return rows.fetch(fetch_last, 0)
+}
+
+// Absolute moves to the n-th row (1 is the first row, -1 is the last row)
+// and reports whether there is a row.
+func (rows *ScrollableRows) Absolute(n int) bool {
+	return rows.fetch(fetch_absolute, n)
+}
+
+// Relative moves n rows from the current row and reports whether there is a row.
+func (rows *ScrollableRows) Relative(n int) bool {
+	return rows.fetch(fetch_relative, n)
+}
+
+// Err returns the error of the last move.
+func (rows *ScrollableRows) Err() error {
+	return rows.err
+}
+
+// Columns returns the column names.
+func (rows *ScrollableRows) Columns() (columns []string, err error) {
+	if rows.stmt == nil {
+		return nil, errors.New("Rows are closed")
+	}
+	err = rawConn(rows.conn, func(fc *firebirdsqlConn) error {
+		columns = rows.stmt.columnNames()
+		return nil
+	})
+	return
+}
+
+// Scan copies the columns of the current row into dest.
+// It supports sql.Scanner and the basic conversions of sql.Rows.Scan.
+func (rows *ScrollableRows) Scan(dest ...interface{}) error {
+	if rows.row == nil {
+		return errors.New("Scan called without a current row")
+	}
+	if len(dest) != len(rows.row) {
+		return errors.New(fmt.Sprintf("Expected %d destination arguments in Scan, not %d", len(rows.row), len(dest)))
+	}
+	for i, v := range rows.row {
+		if err := assignValue(dest[i], v); err != nil {
+			return errors.New(fmt.Sprintf("Scan error on column index %d: %v", i, err))
+		}
+	}
+	return nil
+}
+
+// Close closes the cursor and releases the statement.
+func (rows *ScrollableRows) Close() error {
+	if rows.stmt == nil {
+		return nil
+	}
+	err := rawConn(rows.conn, func(fc *firebirdsqlConn) error {
+		return rows.stmt.Close()
+	})
+	rows.stmt = nil
+	rows.row = nil
+	return err
+}
+
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// assignValue stores src into the pointer dest.
+func assignValue(dest interface{}, src driver.Value) (err error) {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return errors.New("Destination is not a pointer")
+	}
+	dv = dv.Elem()
+
+	if src == nil {
+		switch dv.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			dv.Set(reflect.Zero(dv.Type()))
+			return nil
+		}
+		return errors.New(fmt.Sprintf("Converting NULL to %s is unsupported", dv.Type()))
+	}
+
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dv.Type()) {
+		dv.Set(sv)
+		return nil
+	}
+
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	switch dv.Kind() {
+	case reflect.Ptr:
+		p := reflect.New(dv.Type().Elem())
+		if err = assignValue(p.Interface(), src); err == nil {
+			dv.Set(p)
+		}
+	case reflect.String:
+		dv.SetString(s)
+	case reflect.Slice:
+		if dv.Type() != bytesType {
+			return errors.New(fmt.Sprintf("Unsupported Scan, storing %T into %s", src, dv.Type()))
+		}
+		dv.SetBytes([]byte(s))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, dv.Type().Bits()); err == nil {
+			dv.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, dv.Type().Bits()); err == nil {
+			dv.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, dv.Type().Bits()); err == nil {
+			dv.SetFloat(f)
+		}
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			dv.SetBool(b)
+		}
+	default:
+		err = errors.New(fmt.Sprintf("Unsupported Scan, storing %T into %s", src, dv.Type()))
+	}
+	return
+}
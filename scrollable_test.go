@@ -0,0 +1,137 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAssignValue(t *testing.T) {
+	var i int
+	var i8 int8
+	var f float64
+	var s string
+	var b []byte
+	var p *int64
+	var ns sql.NullString
+
+	if err := assignValue(&i, int32(3)); err != nil || i != 3 {
+		t.Errorf("int: %v %v", i, err)
+	}
+	if err := assignValue(&i8, int64(300)); err == nil {
+		t.Errorf("overflow is not detected: %v", i8)
+	}
+	if err := assignValue(&f, decimal.New(125, -2)); err != nil || f != 1.25 {
+		t.Errorf("float64: %v %v", f, err)
+	}
+	if err := assignValue(&s, int64(42)); err != nil || s != "42" {
+		t.Errorf("string: %v %v", s, err)
+	}
+	if err := assignValue(&b, "abc"); err != nil || string(b) != "abc" {
+		t.Errorf("[]byte: %v %v", b, err)
+	}
+	if err := assignValue(&p, int64(7)); err != nil || p == nil || *p != 7 {
+		t.Errorf("*int64: %v %v", p, err)
+	}
+	if err := assignValue(&p, nil); err != nil || p != nil {
+		t.Errorf("nil *int64: %v %v", p, err)
+	}
+	if err := assignValue(&i, nil); err == nil {
+		t.Errorf("NULL to int is not detected")
+	}
+	if err := assignValue(&ns, "x"); err != nil || !ns.Valid || ns.String != "x" {
+		t.Errorf("sql.NullString: %v %v", ns, err)
+	}
+}
+
+func TestScrollableRows(t *testing.T) {
+	temppath := TempFileName("test_scrollable_rows_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_scrollable_rows (a integer)")
+	for i := 1; i <= 5; i++ {
+		conn.Exec("INSERT INTO test_scrollable_rows (a) VALUES (?)", i)
+	}
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+	c, err := conn.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Error Conn(): %v", err)
+	}
+	defer c.Close()
+
+	rows, err := QueryScrollable(ctx, c, "SELECT a FROM test_scrollable_rows WHERE a >= ? ORDER BY a", 1)
+	if err != nil && strings.Contains(err.Error(), "Firebird 5.0") {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("Error QueryScrollable: %v", err)
+	}
+	defer rows.Close()
+
+	var a int
+	for _, move := range []struct {
+		f        func() bool
+		expected int
+	}{
+		{rows.Next, 1},
+		{rows.Last, 5},
+		{rows.Prior, 4},
+		{func() bool { return rows.Absolute(2) }, 2},
+		{func() bool { return rows.Relative(2) }, 4},
+		{rows.First, 1},
+	} {
+		if !move.f() {
+			t.Fatalf("Error move: %v", rows.Err())
+		}
+		if err = rows.Scan(&a); err != nil {
+			t.Fatalf("Error Scan: %v", err)
+		}
+		if a != move.expected {
+			t.Fatalf("Incorrect row: %v != %v", a, move.expected)
+		}
+	}
+	if rows.Prior() || rows.Err() != nil {
+		t.Fatalf("Prior of the first row must be none: %v", rows.Err())
+	}
+	if !rows.Next() {
+		t.Fatalf("Error Next: %v", rows.Err())
+	}
+}
@@ -0,0 +1,257 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	sqlTokenWord       = iota // keyword, identifier or number
+	sqlTokenQuoted            // string literal or quoted identifier
+	sqlTokenParam             // ?
+	sqlTokenNamedParam        // :name
+	sqlTokenSymbol
+)
+
+type sqlToken struct {
+	kind int
+	text string
+	pos  int // byte offset in the statement
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '$' || (c >= '0' && c <= '9') || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+func isIdentifierStart(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+// scanQuoted returns the end of a quoted string starting at i,
+// a doubled quote character is an escaped one.
+func scanQuoted(query string, i int, quote byte) int {
+	for i++; i < len(query); i++ {
+		if query[i] == quote {
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+// scanAlternativeQuoted returns the end of q'<delimiter>...<delimiter>' string starting at i.
+func scanAlternativeQuoted(query string, i int) int {
+	if i+2 >= len(query) {
+		return len(query)
+	}
+	closing := query[i+2]
+	switch closing {
+	case '(':
+		closing = ')'
+	case '[':
+		closing = ']'
+	case '{':
+		closing = '}'
+	case '<':
+		closing = '>'
+	}
+	end := strings.Index(query[i+3:], string([]byte{closing, '\''}))
+	if end < 0 {
+		return len(query)
+	}
+	return i + 3 + end + 2
+}
+
+// tokenizeSQL splits a statement into tokens, skipping white spaces and comments.
+func tokenizeSQL(query string) (tokens []sqlToken) {
+	i := 0
+	for i < len(query) {
+		c := query[i]
+		start := i
+		kind := sqlTokenSymbol
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+			continue
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(query)
+			}
+			continue
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(query)
+			}
+			continue
+		case c == '\'' || c == '"':
+			kind = sqlTokenQuoted
+			i = scanQuoted(query, i, c)
+		case (c == 'q' || c == 'Q') && i+1 < len(query) && query[i+1] == '\'':
+			kind = sqlTokenQuoted
+			i = scanAlternativeQuoted(query, i)
+		case c == '?':
+			kind = sqlTokenParam
+			i++
+		case c == ':' && i+1 < len(query) && isIdentifierStart(query[i+1]):
+			kind = sqlTokenNamedParam
+			for i++; i < len(query) && isIdentifierChar(query[i]); i++ {
+			}
+		case isIdentifierChar(c):
+			kind = sqlTokenWord
+			for ; i < len(query) && isIdentifierChar(query[i]); i++ {
+			}
+		default:
+			i++
+		}
+		tokens = append(tokens, sqlToken{kind: kind, text: query[start:i], pos: start})
+	}
+	return
+}
+
+// isWord reports whether tokens start with the keywords.
+func isWord(tokens []sqlToken, keywords ...string) bool {
+	if len(tokens) < len(keywords) {
+		return false
+	}
+	for i, keyword := range keywords {
+		if tokens[i].kind != sqlTokenWord || !strings.EqualFold(tokens[i].text, keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPSQLDefinition reports whether the statement defines a PSQL module
+// whose body uses :name as local variables.
+func isPSQLDefinition(tokens []sqlToken) bool {
+	switch {
+	case isWord(tokens, "CREATE", "OR", "ALTER"):
+		tokens = tokens[3:]
+	case isWord(tokens, "CREATE"), isWord(tokens, "ALTER"), isWord(tokens, "RECREATE"):
+		tokens = tokens[1:]
+	default:
+		return false
+	}
+	for _, keyword := range []string{"PROCEDURE", "TRIGGER", "FUNCTION", "PACKAGE"} {
+		if isWord(tokens, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// psqlBodyStart returns the index of the token where the PSQL body of EXECUTE BLOCK starts.
+func psqlBodyStart(tokens []sqlToken) int {
+	if !isWord(tokens, "EXECUTE", "BLOCK") {
+		return len(tokens)
+	}
+	depth := 0
+	for i, tok := range tokens {
+		switch {
+		case tok.text == "(":
+			depth++
+		case tok.text == ")":
+			depth--
+		case depth == 0 && isWord(tokens[i:], "AS"):
+			return i
+		}
+	}
+	return len(tokens)
+}
+
+// isDDL reports whether the statement changes metadata.
+func isDDL(tokens []sqlToken) bool {
+	for _, keyword := range []string{"CREATE", "ALTER", "RECREATE", "DROP", "DECLARE", "COMMENT", "GRANT", "REVOKE"} {
+		if isWord(tokens, keyword) {
+			return true
+		}
+	}
+	return isWord(tokens, "SET", "GENERATOR") || isWord(tokens, "SET", "STATISTICS")
+}
+
+// isImmediate reports whether the statement can be executed by op_execute_immediate,
+// that is it returns no rows and does not end or start the transaction.
+func isImmediate(tokens []sqlToken) bool {
+	if isWord(tokens, "SET") {
+		return !isWord(tokens, "SET", "TRANSACTION") && !isWord(tokens, "SET", "TERM")
+	}
+	return isDDL(tokens)
+}
+
+// isPlainDML reports whether the statement is INSERT, UPDATE, DELETE or MERGE
+// without RETURNING, which returns no rows and does not open a cursor.
+func isPlainDML(tokens []sqlToken) bool {
+	if !isWord(tokens, "INSERT") && !isWord(tokens, "UPDATE") && !isWord(tokens, "DELETE") && !isWord(tokens, "MERGE") {
+		return false
+	}
+	for i := range tokens {
+		if isWord(tokens[i:], "RETURNING") {
+			return false
+		}
+	}
+	return true
+}
+
+// rewriteNamedParams replaces :name parameters with ? and returns the names in order.
+// String literals, quoted identifiers, comments and PSQL bodies are left as they are.
+func rewriteNamedParams(query string) (string, []string, error) {
+	tokens := tokenizeSQL(query)
+	if isPSQLDefinition(tokens) {
+		return query, nil, nil
+	}
+
+	var names []string
+	var b strings.Builder
+	positional := false
+	last := 0
+	for _, tok := range tokens[:psqlBodyStart(tokens)] {
+		switch tok.kind {
+		case sqlTokenParam:
+			positional = true
+		case sqlTokenNamedParam:
+			names = append(names, tok.text[1:])
+			b.WriteString(query[last:tok.pos])
+			b.WriteString("?")
+			last = tok.pos + len(tok.text)
+		}
+	}
+	if len(names) == 0 {
+		return query, nil, nil
+	}
+	if positional {
+		return query, nil, errors.New("Cannot mix ? and :name parameters")
+	}
+	b.WriteString(query[last:])
+
+	return b.String(), names, nil
+}
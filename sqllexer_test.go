@@ -0,0 +1,133 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestRewriteNamedParams(t *testing.T) {
+	for _, tc := range []struct {
+		query    string
+		expected string
+		names    []string
+	}{
+		{"SELECT * FROM t WHERE a = :a AND b = :b2 OR a = :a", "SELECT * FROM t WHERE a = ? AND b = ? OR a = ?", []string{"a", "b2", "a"}},
+		{"SELECT ':a', \":a\", q'{:a}' FROM t WHERE a=:a", "SELECT ':a', \":a\", q'{:a}' FROM t WHERE a=?", []string{"a"}},
+		{"SELECT 'it''s :a' FROM t -- :b\nWHERE /* :c */ a = :d", "SELECT 'it''s :a' FROM t -- :b\nWHERE /* :c */ a = ?", []string{"d"}},
+		{"SELECT * FROM t WHERE a = ?", "SELECT * FROM t WHERE a = ?", nil},
+		{
+			"EXECUTE BLOCK (x INTEGER = :x) RETURNS (y INTEGER) AS BEGIN y = :x; SUSPEND; END",
+			"EXECUTE BLOCK (x INTEGER = ?) RETURNS (y INTEGER) AS BEGIN y = :x; SUSPEND; END",
+			[]string{"x"},
+		},
+		{"CREATE OR ALTER PROCEDURE p (a INTEGER) AS DECLARE b INTEGER; BEGIN b = :a; END", "CREATE OR ALTER PROCEDURE p (a INTEGER) AS DECLARE b INTEGER; BEGIN b = :a; END", nil},
+		{"recreate trigger t for x before insert as begin new.a = :b; end", "recreate trigger t for x before insert as begin new.a = :b; end", nil},
+	} {
+		query, names, err := rewriteNamedParams(tc.query)
+		if err != nil {
+			t.Errorf("%s: %v", tc.query, err)
+		}
+		if query != tc.expected || !reflect.DeepEqual(names, tc.names) {
+			t.Errorf("rewriteNamedParams(%q) = %q, %v", tc.query, query, names)
+		}
+	}
+
+	_, _, err := rewriteNamedParams("SELECT * FROM t WHERE a = :a AND b = ?")
+	if err == nil {
+		t.Errorf("Mixed parameters are not detected")
+	}
+}
+
+func TestBindNamedValues(t *testing.T) {
+	args, err := bindNamedValues([]string{"a", "b", "a"}, []driver.NamedValue{
+		{Name: "b", Ordinal: 1, Value: int64(2)},
+		{Name: "a", Ordinal: 2, Value: int64(1)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(args, []driver.Value{int64(1), int64(2), int64(1)}) {
+		t.Errorf("Incorrect args:%v", args)
+	}
+
+	for _, namedargs := range [][]driver.NamedValue{
+		{{Name: "a", Ordinal: 1, Value: int64(1)}},
+		{{Name: "a", Ordinal: 1, Value: int64(1)}, {Name: "b", Ordinal: 2, Value: int64(2)}, {Name: "c", Ordinal: 3, Value: int64(3)}},
+		{{Name: "a", Ordinal: 1, Value: int64(1)}, {Ordinal: 2, Value: int64(2)}},
+	} {
+		if _, err = bindNamedValues([]string{"a", "b"}, namedargs); err == nil {
+			t.Errorf("Error is not detected:%v", namedargs)
+		}
+	}
+	if _, err = bindNamedValues(nil, []driver.NamedValue{{Name: "a", Ordinal: 1, Value: int64(1)}}); err == nil {
+		t.Errorf("Named argument for ? is not detected")
+	}
+}
+
+func TestIsImmediate(t *testing.T) {
+	for _, tc := range []struct {
+		query    string
+		expected bool
+	}{
+		{"CREATE TABLE foo (a INTEGER)", true},
+		{"-- comment\n drop table foo", true},
+		{"SET GENERATOR g TO 1", true},
+		{"SET TIME ZONE 'UTC'", true},
+		{"COMMENT ON TABLE foo IS 'bar'", true},
+		{"SET TRANSACTION READ ONLY", false},
+		{"SET TERM ^", false},
+		{"SELECT * FROM rdb$database", false},
+		{"INSERT INTO foo VALUES (1)", false},
+		{"COMMIT", false},
+		{"EXECUTE BLOCK AS BEGIN END", false},
+	} {
+		if isImmediate(tokenizeSQL(tc.query)) != tc.expected {
+			t.Errorf("isImmediate(%q) != %v", tc.query, tc.expected)
+		}
+	}
+}
+
+func TestIsPlainDML(t *testing.T) {
+	for _, tc := range []struct {
+		query    string
+		expected bool
+	}{
+		{"INSERT INTO foo VALUES (1)", true},
+		{"update foo set a = 1", true},
+		{"UPDATE OR INSERT INTO foo (a) VALUES (1) MATCHING (a)", true},
+		{"DELETE FROM foo WHERE a = 'RETURNING'", true},
+		{"MERGE INTO foo USING bar ON foo.a = bar.a WHEN MATCHED THEN DELETE", true},
+		{"INSERT INTO foo VALUES (1) RETURNING a", false},
+		{"SELECT * FROM foo", false},
+		{"EXECUTE PROCEDURE foo", false},
+		{"CREATE TABLE foo (a INTEGER)", false},
+	} {
+		if isPlainDML(tokenizeSQL(tc.query)) != tc.expected {
+			t.Errorf("isPlainDML(%q) != %v", tc.query, tc.expected)
+		}
+	}
+}
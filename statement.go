@@ -24,7 +24,15 @@ CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
 package firebirdsql
 
 import (
+	"bytes"
+	"container/list"
+	"database/sql"
 	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 
 	"context"
 )
@@ -34,8 +42,14 @@ type firebirdsqlStmt struct {
 	stmtHandle int32
 	tx         *firebirdsqlTx
 	xsqlda     []xSQLVAR
+	bindXsqlda []xSQLVAR
+	paramNames []string // :name parameters in order, nil for ? parameters
 	blr        []byte
 	stmtType   int32
+	stmtFlags  int32  // isc_info_sql_stmt_flags (Firebird 4.0+)
+	sql        string // SQL text given to prepare, the key of stmtCache
+	cursorOpen bool
+	cursorName string
 }
 
 func (stmt *firebirdsqlStmt) Close() (err error) {
@@ -49,70 +63,491 @@ func (stmt *firebirdsqlStmt) Close() (err error) {
 	return
 }
 
+func (stmt *firebirdsqlStmt) columnNames() []string {
+	columns := make([]string, len(stmt.xsqlda))
+	for i, x := range stmt.xsqlda {
+		columns[i] = x.aliasname
+		if stmt.tx.fc.columnNameToLower {
+			columns[i] = strings.ToLower(columns[i])
+		}
+	}
+	return columns
+}
+
+// readRow copies a fetched row to dest, reading the contents of blobs and arrays.
+func (stmt *firebirdsqlStmt) readRow(row []driver.Value, dest []driver.Value) (err error) {
+	for i, v := range row {
+		if stmt.xsqlda[i].sqltype == SQL_TYPE_ARRAY && v != nil {
+			var d *arrayDesc
+			d, err = stmt.tx.fc.arrayDesc(stmt.xsqlda[i].relname, stmt.xsqlda[i].fieldname)
+			if err == nil {
+				dest[i], err = stmt.wp.getSlice(v.([]byte), stmt.tx.transHandle, d)
+			}
+		} else if stmt.xsqlda[i].sqltype == SQL_TYPE_BLOB && v != nil {
+			blobId := v.([]byte)
+			var blob []byte
+			blob, err = stmt.wp.getBlobSegments(blobId, stmt.tx.transHandle)
+			if stmt.xsqlda[i].sqlsubtype == 1 {
+				dest[i] = bytes.NewBuffer(blob).String()
+			} else {
+				dest[i] = blob
+			}
+
+		} else {
+			dest[i] = v
+		}
+	}
+	return
+}
+
+// hasCursor reports whether executing the statement opens a cursor.
+// DML with RETURNING of multiple rows (Firebird 5.0+) has a cursor too.
+func (stmt *firebirdsqlStmt) hasCursor() bool {
+	return stmt.stmtType == isc_info_sql_stmt_select || stmt.stmtType == isc_info_sql_stmt_select_for_upd ||
+		stmt.stmtFlags&stmt_flag_has_cursor != 0
+}
+
+// isSingleton reports whether the statement returns a row by op_execute2.
+func (stmt *firebirdsqlStmt) isSingleton() bool {
+	return stmt.stmtType == isc_info_sql_stmt_exec_procedure && !stmt.hasCursor()
+}
+
+// drainCursor fetches all rows of DML with RETURNING, whose rows are
+// processed while they are fetched, and returns the last row.
+func (stmt *firebirdsqlStmt) drainCursor() (last []driver.Value, err error) {
+	for more := true; more && err == nil; {
+		var chunk *list.List
+		stmt.wp.opFetch(stmt.stmtHandle, stmt.blr, int32(defaultFetchSize))
+		chunk, more, err = stmt.wp.opFetchResponse(stmt.stmtHandle, stmt.tx.transHandle, stmt.xsqlda)
+		if err == nil && chunk.Len() > 0 {
+			last = chunk.Back().Value.([]driver.Value)
+		}
+	}
+	return
+}
+
+// isDMLCursor reports whether the statement is DML with RETURNING reported with a cursor.
+func (stmt *firebirdsqlStmt) isDMLCursor() bool {
+	return stmt.hasCursor() && stmt.stmtType != isc_info_sql_stmt_select && stmt.stmtType != isc_info_sql_stmt_select_for_upd
+}
+
+// closeCursor closes the cursor to execute the statement again.
+func (stmt *firebirdsqlStmt) closeCursor() (err error) {
+	if !stmt.cursorOpen {
+		return
+	}
+	stmt.cursorOpen = false
+	stmt.wp.opFreeStatement(stmt.stmtHandle, 1) // DSQL_close
+	if stmt.wp.acceptType == ptype_lazy_send {
+		stmt.wp.lazyResponseCount++
+	} else {
+		_, _, _, err = stmt.wp.opResponse()
+	}
+
+	return
+}
+
 func (stmt *firebirdsqlStmt) NumInput() int {
-	return -1
+	if stmt.isSingleton() {
+		// sql.Out arguments are given besides the inputs, paramsToBlr checks the count
+		return -1
+	}
+	if stmt.paramNames != nil {
+		names := make(map[string]bool)
+		for _, name := range stmt.paramNames {
+			names[name] = true
+		}
+		return len(names)
+	}
+	return len(stmt.bindXsqlda)
 }
 
-func (stmt *firebirdsqlStmt) exec(ctx context.Context, args []driver.Value) (result driver.Result, err error) {
-	stmt.wp.opExecute(stmt.stmtHandle, stmt.tx.transHandle, args)
-	_, _, _, err = stmt.wp.opResponse()
+// interfacesToNamedValues converts arguments as database/sql does for the driver.
+func interfacesToNamedValues(args []interface{}) ([]driver.NamedValue, error) {
+	namedargs := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		namedargs[i].Ordinal = i + 1
+		if named, ok := arg.(sql.NamedArg); ok {
+			namedargs[i].Name = named.Name
+			arg = named.Value
+		}
+		if out, ok := arg.(sql.Out); ok {
+			namedargs[i].Value = out
+			continue
+		}
+		v, err := driver.DefaultParameterConverter.ConvertValue(arg)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Argument %d: %v", i+1, err))
+		}
+		namedargs[i].Value = v
+	}
+	return namedargs, nil
+}
+
+// outArg is a sql.Out argument which receives an output of EXECUTE PROCEDURE.
+type outArg struct {
+	name string
+	dest interface{}
+}
+
+// splitOutArgs removes sql.Out arguments from namedargs.
+// The value of sql.Out with In is left as the input argument.
+func splitOutArgs(namedargs []driver.NamedValue, named bool) (inargs []driver.NamedValue, outs []outArg, err error) {
+	for _, nv := range namedargs {
+		out, ok := nv.Value.(sql.Out)
+		if !ok {
+			inargs = append(inargs, nv)
+			continue
+		}
+		outs = append(outs, outArg{name: nv.Name, dest: out.Dest})
+		if !out.In {
+			continue
+		}
+		dv := reflect.ValueOf(out.Dest)
+		if dv.Kind() != reflect.Ptr || dv.IsNil() {
+			return nil, nil, errors.New(fmt.Sprintf("Argument %d: sql.Out destination is not a pointer", nv.Ordinal))
+		}
+		nv.Value, err = driver.DefaultParameterConverter.ConvertValue(dv.Elem().Interface())
+		if err != nil {
+			return nil, nil, errors.New(fmt.Sprintf("Argument %d: %v", nv.Ordinal, err))
+		}
+		if !named {
+			nv.Name = ""
+		}
+		inargs = append(inargs, nv)
+	}
+	return
+}
+
+func valuesToNamedValues(args []driver.Value) []driver.NamedValue {
+	namedargs := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		namedargs[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return namedargs
+}
+
+// bindNamedValues orders arguments to the parameters of the statement.
+func bindNamedValues(names []string, namedargs []driver.NamedValue) ([]driver.Value, error) {
+	if names == nil {
+		sort.SliceStable(namedargs, func(i, j int) bool {
+			return namedargs[i].Ordinal < namedargs[j].Ordinal
+		})
+		args := make([]driver.Value, len(namedargs))
+		for i, nv := range namedargs {
+			if nv.Name != "" {
+				return nil, errors.New(fmt.Sprintf("Named argument %s needs :%s parameter", nv.Name, nv.Name))
+			}
+			args[i] = nv.Value
+		}
+		return args, nil
+	}
+
+	values := make(map[string]driver.Value)
+	for _, nv := range namedargs {
+		if nv.Name == "" {
+			return nil, errors.New(fmt.Sprintf("Argument %d is not named", nv.Ordinal))
+		}
+		values[nv.Name] = nv.Value
+	}
+	used := make(map[string]bool)
+	args := make([]driver.Value, len(names))
+	for i, name := range names {
+		v, ok := values[name]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("Missing named argument :%s", name))
+		}
+		args[i] = v
+		used[name] = true
+	}
+	for _, nv := range namedargs {
+		if !used[nv.Name] {
+			return nil, errors.New(fmt.Sprintf("Named argument %s is not used", nv.Name))
+		}
+	}
+	return args, nil
+}
+
+// bindParams starts the transaction if needed and returns the message of the arguments.
+func (stmt *firebirdsqlStmt) bindParams(namedargs []driver.NamedValue) (blr []byte, values []byte, err error) {
+	args, err := bindNamedValues(stmt.paramNames, namedargs)
 	if err != nil {
 		return
 	}
-	stmt.wp.opInfoSql(stmt.stmtHandle, []byte{isc_info_sql_records})
-	_, _, buf, err := stmt.wp.opResponse()
+	err = stmt.tx.beginIfNeeded()
+	if err != nil {
+		return
+	}
+	if err = stmt.putArrays(args); err != nil {
+		return
+	}
+	blr, values, err = stmt.wp.paramsToBlr(stmt.tx.transHandle, stmt.bindXsqlda, args, stmt.wp.protocolVersion)
 	if err != nil {
 		return
 	}
+	err = stmt.closeCursor()
+	return
+}
 
-	var rowcount int64
-	if len(buf) >= 32 {
-		if stmt.stmtType == isc_info_sql_stmt_select {
-			rowcount = int64(bytes_to_int32(buf[20:24]))
-		} else {
-			rowcount = int64(bytes_to_int32(buf[27:31]) + bytes_to_int32(buf[6:10]) + bytes_to_int32(buf[13:17]))
+// putArrays writes slices given to ARRAY parameters and replaces them with the ids.
+func (stmt *firebirdsqlStmt) putArrays(args []driver.Value) error {
+	for i, arg := range args {
+		if !isArrayValue(arg) {
+			continue
 		}
-	} else {
-		rowcount = 0
+		if i >= len(stmt.bindXsqlda) || stmt.bindXsqlda[i].sqltype != SQL_TYPE_ARRAY {
+			return errors.New(fmt.Sprintf("Argument %d: %T is supported only by ARRAY parameters", i+1, arg))
+		}
+		d, err := stmt.tx.fc.arrayDesc(stmt.bindXsqlda[i].relname, stmt.bindXsqlda[i].fieldname)
+		if err != nil {
+			return err
+		}
+		id, err := stmt.wp.putSlice(arg, stmt.tx.transHandle, d)
+		if err != nil {
+			return err
+		}
+		args[i] = arrayId(id)
 	}
+	return nil
+}
 
-	result = &firebirdsqlResult{
-		affectedRows: rowcount,
+func (stmt *firebirdsqlStmt) setCursorName(name string) (err error) {
+	stmt.wp.opSetCursor(stmt.stmtHandle, name)
+	_, _, _, err = stmt.wp.opResponse()
+	if err == nil {
+		stmt.cursorName = name
 	}
 	return
 }
 
+func (stmt *firebirdsqlStmt) execute(blr []byte, values []byte, cursorFlags int32) (err error) {
+	stmt.wp.opExecute(stmt.stmtHandle, stmt.tx.transHandle, blr, values, cursorFlags)
+	_, _, _, err = stmt.wp.opResponse()
+	stmt.cursorOpen = err == nil && stmt.hasCursor()
+	return
+}
+
+// executeProcedure executes EXECUTE PROCEDURE and assigns the outputs to outs.
+func (stmt *firebirdsqlStmt) executeProcedure(blr []byte, values []byte, outs []outArg) (err error) {
+	stmt.wp.opExecute2(stmt.stmtHandle, stmt.tx.transHandle, blr, values, stmt.blr)
+	row, err := stmt.wp.opSqlResponse(stmt.xsqlda)
+	if err != nil {
+		return
+	}
+	_, _, _, err = stmt.wp.opResponse()
+	if err != nil || len(outs) == 0 {
+		return
+	}
+	if row == nil {
+		return errors.New("Procedure returned no output")
+	}
+	dest := make([]driver.Value, len(row))
+	if err = stmt.readRow(row, dest); err != nil {
+		return
+	}
+	return stmt.assignOuts(outs, dest)
+}
+
+// assignOuts assigns output columns to sql.Out arguments by the name, or in order if not named.
+func (stmt *firebirdsqlStmt) assignOuts(outs []outArg, row []driver.Value) error {
+	used := make([]bool, len(row))
+	next := 0
+	for _, out := range outs {
+		i := -1
+		if out.name != "" {
+			for j := range stmt.xsqlda {
+				if strings.EqualFold(stmt.xsqlda[j].aliasname, out.name) {
+					i = j
+					break
+				}
+			}
+			if i < 0 {
+				return errors.New(fmt.Sprintf("No output parameter %s", out.name))
+			}
+		} else {
+			for next < len(used) && used[next] {
+				next++
+			}
+			if next >= len(used) {
+				return errors.New(fmt.Sprintf("Expected at most %d output arguments", len(row)))
+			}
+			i = next
+		}
+		if used[i] {
+			return errors.New(fmt.Sprintf("Output parameter %s is assigned twice", stmt.xsqlda[i].aliasname))
+		}
+		used[i] = true
+		if err := assignValue(out.dest, row[i]); err != nil {
+			return errors.New(fmt.Sprintf("Output parameter %s: %v", stmt.xsqlda[i].aliasname, err))
+		}
+	}
+	return nil
+}
+
+func (stmt *firebirdsqlStmt) recordsInfo() (buf []byte, err error) {
+	stmt.wp.opInfoSql(stmt.stmtHandle, []byte{isc_info_sql_records}, int32(BUFFER_LEN))
+	_, _, buf, err = stmt.wp.opResponse()
+	return
+}
+
+func (stmt *firebirdsqlStmt) exec(ctx context.Context, namedargs []driver.NamedValue) (result driver.Result, err error) {
+	namedargs, outs, err := splitOutArgs(namedargs, stmt.paramNames != nil)
+	if err != nil {
+		return
+	}
+	if len(outs) > 0 && !stmt.isSingleton() && !stmt.isDMLCursor() {
+		return nil, errors.New("sql.Out is supported only by EXECUTE PROCEDURE and DML with RETURNING")
+	}
+	blr, values, err := stmt.bindParams(namedargs)
+	if err != nil {
+		return
+	}
+	var buf []byte
+	switch {
+	case stmt.isSingleton():
+		err = stmt.executeProcedure(blr, values, outs)
+		if err == nil {
+			buf, err = stmt.recordsInfo()
+		}
+	case stmt.hasCursor():
+		err = stmt.execute(blr, values, 0)
+		if err == nil && stmt.cursorOpen && stmt.isDMLCursor() {
+			// the outputs are of the last row, they are left as they are without rows
+			var row []driver.Value
+			row, err = stmt.drainCursor()
+			if err == nil && row != nil && len(outs) > 0 {
+				dest := make([]driver.Value, len(row))
+				if err = stmt.readRow(row, dest); err == nil {
+					err = stmt.assignOuts(outs, dest)
+				}
+			}
+		}
+		if err == nil {
+			buf, err = stmt.recordsInfo()
+		}
+	default:
+		// execute and get the record counts in a round trip
+		stmt.wp.deferPackets()
+		stmt.wp.opExecute(stmt.stmtHandle, stmt.tx.transHandle, blr, values, 0)
+		stmt.wp.opInfoSql(stmt.stmtHandle, []byte{isc_info_sql_records}, int32(BUFFER_LEN))
+		_, _, _, err = stmt.wp.opResponse()
+		var infoErr error
+		_, _, buf, infoErr = stmt.wp.opResponse()
+		if err == nil {
+			err = infoErr
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	return newFirebirdsqlResult(stmt.stmtType, buf)
+}
+
 func (stmt *firebirdsqlStmt) Exec(args []driver.Value) (result driver.Result, err error) {
-	return stmt.exec(context.Background(), args)
+	return stmt.exec(context.Background(), valuesToNamedValues(args))
 }
 
-func (stmt *firebirdsqlStmt) query(ctx context.Context, args []driver.Value) (driver.Rows, error) {
+func (stmt *firebirdsqlStmt) query(ctx context.Context, namedargs []driver.NamedValue) (driver.Rows, error) {
 	var rows driver.Rows
-	var err error
 	var result []driver.Value
 
-	if stmt.stmtType == isc_info_sql_stmt_exec_procedure {
-		stmt.wp.opExecute2(stmt.stmtHandle, stmt.tx.transHandle, args, stmt.blr)
+	for _, nv := range namedargs {
+		if _, ok := nv.Value.(sql.Out); ok {
+			return nil, errors.New("sql.Out is supported only by Exec")
+		}
+	}
+	blr, values, err := stmt.bindParams(namedargs)
+	if err != nil {
+		return nil, err
+	}
+
+	if stmt.isSingleton() {
+		stmt.wp.opExecute2(stmt.stmtHandle, stmt.tx.transHandle, blr, values, stmt.blr)
 		result, err = stmt.wp.opSqlResponse(stmt.xsqlda)
-		rows = newFirebirdsqlRows(stmt, result)
+		if err != nil {
+			return nil, err
+		}
+		rows = newFirebirdsqlRows(stmt, result, 0)
 		_, _, _, err = stmt.wp.opResponse()
 	} else {
-		stmt.wp.opExecute(stmt.stmtHandle, stmt.tx.transHandle, args)
+		cursorName, named := cursorNameFromContext(ctx)
+		if named {
+			err = stmt.setCursorName(cursorName)
+			if err != nil {
+				return nil, err
+			}
+		}
+		fetchSize, ok := fetchSizeFromContext(ctx)
+		if !ok {
+			fetchSize = stmt.tx.fc.fetchSize
+		}
+		if named {
+			// the server position must be the current row for WHERE CURRENT OF
+			fetchSize = 1
+		}
+		r := newFirebirdsqlRows(stmt, nil, fetchSize)
+		if !stmt.hasCursor() {
+			return r, stmt.execute(blr, values, 0)
+		}
+
+		// execute and fetch the first rows in a round trip
+		stmt.wp.deferPackets()
+		stmt.wp.opExecute(stmt.stmtHandle, stmt.tx.transHandle, blr, values, 0)
+		stmt.wp.opFetch(stmt.stmtHandle, stmt.blr, int32(r.nextFetchSize()))
 		_, _, _, err = stmt.wp.opResponse()
-		rows = newFirebirdsqlRows(stmt, nil)
+		chunk, more, fetchErr := stmt.wp.opFetchResponse(stmt.stmtHandle, stmt.tx.transHandle, stmt.xsqlda)
+		if err != nil {
+			return nil, err
+		}
+		stmt.cursorOpen = true
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		r.firstChunk, r.moreData = chunk, more
+		rows = r
 	}
 	return rows, err
 }
 
 func (stmt *firebirdsqlStmt) Query(args []driver.Value) (rows driver.Rows, err error) {
-	return stmt.query(context.Background(), args)
+	return stmt.query(context.Background(), valuesToNamedValues(args))
 }
 
 func newFirebirdsqlStmt(fc *firebirdsqlConn, query string) (stmt *firebirdsqlStmt, err error) {
+	stmt, err = sendPrepare(fc, query)
+	if err != nil {
+		return
+	}
+	err = stmt.prepareResponse()
+	if err != nil {
+		stmt.Close()
+	}
+	return
+}
+
+// sendPrepare allocates and prepares a statement. In lazy_send mode the requests are deferred,
+// the caller may add requests for the statement before prepareResponse receives the responses.
+func sendPrepare(fc *firebirdsqlConn, query string) (stmt *firebirdsqlStmt, err error) {
 	stmt = new(firebirdsqlStmt)
 	stmt.wp = fc.wp
 	stmt.tx = fc.tx
+	stmt.sql = query
+
+	query, stmt.paramNames, err = rewriteNamedParams(query)
+	if err != nil {
+		return
+	}
+
+	err = stmt.tx.beginIfNeeded()
+	if err != nil {
+		return
+	}
 
+	if fc.wp.acceptType == ptype_lazy_send {
+		// allocate and prepare in a round trip
+		fc.wp.deferPackets()
+	}
 	fc.wp.opAllocateStatement()
 
 	if fc.wp.acceptType == ptype_lazy_send {
@@ -120,22 +555,117 @@ func newFirebirdsqlStmt(fc *firebirdsqlConn, query string) (stmt *firebirdsqlStm
 		stmt.stmtHandle = -1
 	} else {
 		stmt.stmtHandle, _, _, err = fc.wp.opResponse()
+		if err != nil {
+			return
+		}
 	}
 
 	fc.wp.opPrepareStatement(stmt.stmtHandle, stmt.tx.transHandle, query)
+	return
+}
 
-	if fc.wp.acceptType == ptype_lazy_send && fc.wp.lazyResponseCount > 0 {
-		fc.wp.lazyResponseCount--
-		stmt.stmtHandle, _, _, _ = fc.wp.opResponse()
+// prepareResponse receives the responses of sendPrepare and describes the statement.
+func (stmt *firebirdsqlStmt) prepareResponse() (err error) {
+	if stmt.wp.acceptType == ptype_lazy_send && stmt.wp.lazyResponseCount > 0 {
+		stmt.wp.lazyResponseCount--
+		stmt.stmtHandle, _, _, _ = stmt.wp.opResponse()
 	}
 
-	_, _, buf, err := fc.wp.opResponse()
+	_, _, buf, err := stmt.wp.opResponse()
 	if err != nil {
 		return
 	}
 
-	stmt.stmtType, stmt.xsqlda, err = fc.wp.parse_xsqlda(buf, stmt.stmtHandle)
+	stmt.stmtType, stmt.stmtFlags, stmt.xsqlda, stmt.bindXsqlda, err = stmt.wp.parse_xsqlda(buf, stmt.stmtHandle)
 	stmt.blr = calcBlr(stmt.xsqlda)
 
 	return
 }
+
+// execNewStmt prepares query and executes it without arguments in a round trip
+// in lazy_send mode. query must not return rows, see isPlainDML.
+func execNewStmt(fc *firebirdsqlConn, query string) (stmt *firebirdsqlStmt, result driver.Result, err error) {
+	stmt, err = sendPrepare(fc, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the handle -1 is the statement allocated by the deferred request
+	fc.wp.opExecute(stmt.stmtHandle, stmt.tx.transHandle, nil, nil, 0)
+	fc.wp.opInfoSql(stmt.stmtHandle, []byte{isc_info_sql_records}, int32(BUFFER_LEN))
+
+	err = stmt.prepareResponse()
+	_, _, _, execErr := fc.wp.opResponse()
+	_, _, buf, infoErr := fc.wp.opResponse()
+	if err == nil && len(stmt.bindXsqlda) > 0 {
+		err = errors.New(fmt.Sprintf("Expected %d arguments, got 0", len(stmt.bindXsqlda)))
+	}
+	if err == nil {
+		err = execErr
+	}
+	if err == nil {
+		err = infoErr
+	}
+	if err == nil {
+		result, err = newFirebirdsqlResult(stmt.stmtType, buf)
+	}
+	if err != nil {
+		stmt.Close()
+		return nil, nil, err
+	}
+	return
+}
+
+// stmtCache keeps prepared statements of a connection by SQL text in LRU order.
+// A statement is taken out of the cache while it is in use.
+type stmtCache struct {
+	size  int
+	lru   *list.List // *firebirdsqlStmt, most recently used first
+	stmts map[string]*list.Element
+}
+
+func newStmtCache(size int) *stmtCache {
+	return &stmtCache{
+		size:  size,
+		lru:   list.New(),
+		stmts: make(map[string]*list.Element),
+	}
+}
+
+// get takes the statement for query out of the cache.
+func (c *stmtCache) get(query string) *firebirdsqlStmt {
+	e, ok := c.stmts[query]
+	if !ok {
+		return nil
+	}
+	c.lru.Remove(e)
+	delete(c.stmts, query)
+	return e.Value.(*firebirdsqlStmt)
+}
+
+// contains reports whether a statement for query is in the cache.
+func (c *stmtCache) contains(query string) bool {
+	_, ok := c.stmts[query]
+	return ok
+}
+
+// put returns the statement to the cache and returns statements to be dropped.
+func (c *stmtCache) put(stmt *firebirdsqlStmt) (evicted []*firebirdsqlStmt) {
+	if _, ok := c.stmts[stmt.sql]; ok || c.size <= 0 {
+		return []*firebirdsqlStmt{stmt}
+	}
+	c.stmts[stmt.sql] = c.lru.PushFront(stmt)
+	for c.lru.Len() > c.size {
+		evicted = append(evicted, c.get(c.lru.Back().Value.(*firebirdsqlStmt).sql))
+	}
+	return
+}
+
+// clear removes all statements and returns them to be dropped.
+func (c *stmtCache) clear() (evicted []*firebirdsqlStmt) {
+	for e := c.lru.Front(); e != nil; e = e.Next() {
+		evicted = append(evicted, e.Value.(*firebirdsqlStmt))
+	}
+	c.lru.Init()
+	c.stmts = make(map[string]*list.Element)
+	return
+}
@@ -0,0 +1,51 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"testing"
+)
+
+func TestStmtCache(t *testing.T) {
+	c := newStmtCache(2)
+	a := &firebirdsqlStmt{sql: "a"}
+	b := &firebirdsqlStmt{sql: "b"}
+	if evicted := c.put(a); len(evicted) != 0 {
+		t.Fatalf("Incorrect evicted: %v", evicted)
+	}
+	c.put(b)
+	if c.get("a") != a || c.get("a") != nil {
+		t.Fatalf("get must take the statement out of the cache")
+	}
+	c.put(a)
+	if evicted := c.put(&firebirdsqlStmt{sql: "a"}); len(evicted) != 1 || evicted[0] == a {
+		t.Fatalf("Duplicated statement must be dropped: %v", evicted)
+	}
+	if evicted := c.put(&firebirdsqlStmt{sql: "c"}); len(evicted) != 1 || evicted[0] != b {
+		t.Fatalf("Least recently used statement must be evicted: %v", evicted)
+	}
+	if evicted := c.clear(); len(evicted) != 2 || c.get("a") != nil {
+		t.Fatalf("Incorrect clear: %v", evicted)
+	}
+}
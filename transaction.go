@@ -23,80 +23,411 @@ CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
 
 package firebirdsql
 
-type firebirdsqlTx struct {
-	fc             *firebirdsqlConn
-	isolationLevel int
-	isAutocommit   bool
-	transHandle    int32
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// TableReservation reserves a table at transaction start.
+type TableReservation struct {
+	Table string
+	Write bool // lock_write if true, otherwise lock_read
+	Mode  int  // RESERVATION_SHARED, RESERVATION_PROTECTED or RESERVATION_EXCLUSIVE
 }
 
-func (tx *firebirdsqlTx) begin() (err error) {
-	var tpb []byte
-	switch tx.isolationLevel {
-	case ISOLATION_LEVEL_READ_COMMITED_LEGACY:
-		tpb = []byte{
-			byte(isc_tpb_version3),
-			byte(isc_tpb_write),
-			byte(isc_tpb_wait),
-			byte(isc_tpb_read_committed),
-			byte(isc_tpb_no_rec_version),
+// TransactionOptions describes the transaction parameter block used to start a transaction.
+//
+// Pass it to BeginTx with WithTransactionOptions, or set the connection default
+// with the transaction_* DSN parameters.
+type TransactionOptions struct {
+	Isolation    int // ISOLATION_LEVEL_*
+	ReadOnly     bool
+	NoWait       bool
+	LockTimeout  int // seconds, 0 means wait forever. Ignored with NoWait.
+	Reservations []TableReservation
+	NoAutoUndo   bool
+	IgnoreLimbo  bool
+	AutoCommit   bool
+
+	// AtSnapshotNumber starts a REPEATABLE_READ (snapshot) transaction that sees the same
+	// data as the transaction with this snapshot number, see SnapshotNumber. Firebird 4.0+
+	AtSnapshotNumber int64
+
+	// CommitRetaining makes Commit and Rollback use op_commit_retaining/op_rollback_retaining,
+	// which keeps the server transaction (and the oldest active transaction) alive.
+	CommitRetaining bool
+}
+
+func defaultTransactionOptions() TransactionOptions {
+	return TransactionOptions{Isolation: ISOLATION_LEVEL_READ_COMMITED}
+}
+
+func (opts *TransactionOptions) tpb() ([]byte, error) {
+	tpb := []byte{byte(isc_tpb_version3)}
+
+	if opts.ReadOnly || opts.Isolation == ISOLATION_LEVEL_READ_COMMITED_RO {
+		tpb = append(tpb, byte(isc_tpb_read))
+	} else {
+		tpb = append(tpb, byte(isc_tpb_write))
+	}
+
+	if opts.NoWait {
+		tpb = append(tpb, byte(isc_tpb_nowait))
+	} else {
+		tpb = append(tpb, byte(isc_tpb_wait))
+		if opts.LockTimeout < 0 {
+			return nil, errors.New("Invalid lock timeout")
 		}
-	case ISOLATION_LEVEL_READ_COMMITED:
-		tpb = []byte{
-			byte(isc_tpb_version3),
-			byte(isc_tpb_write),
-			byte(isc_tpb_wait),
-			byte(isc_tpb_read_committed),
-			byte(isc_tpb_rec_version),
+		if opts.LockTimeout > 0 {
+			tpb = append(tpb, byte(isc_tpb_lock_timeout), 4)
+			tpb = append(tpb, int32_to_bytes(int32(opts.LockTimeout))...)
 		}
+	}
+
+	switch opts.Isolation {
+	case ISOLATION_LEVEL_READ_COMMITED_LEGACY:
+		tpb = append(tpb, byte(isc_tpb_read_committed), byte(isc_tpb_no_rec_version))
+	case ISOLATION_LEVEL_READ_COMMITED, ISOLATION_LEVEL_READ_COMMITED_RO:
+		tpb = append(tpb, byte(isc_tpb_read_committed), byte(isc_tpb_rec_version))
+	case ISOLATION_LEVEL_READ_CONSISTENCY:
+		tpb = append(tpb, byte(isc_tpb_read_committed), byte(isc_tpb_read_consistency))
 	case ISOLATION_LEVEL_REPEATABLE_READ:
-		tpb = []byte{
-			byte(isc_tpb_version3),
-			byte(isc_tpb_write),
-			byte(isc_tpb_wait),
-			byte(isc_tpb_concurrency),
-		}
+		tpb = append(tpb, byte(isc_tpb_concurrency))
 	case ISOLATION_LEVEL_SERIALIZABLE:
-		tpb = []byte{
-			byte(isc_tpb_version3),
-			byte(isc_tpb_write),
-			byte(isc_tpb_wait),
-			byte(isc_tpb_consistency),
+		tpb = append(tpb, byte(isc_tpb_consistency))
+	default:
+		return nil, errors.New("This isolation level is not supported.")
+	}
+
+	if opts.AtSnapshotNumber != 0 {
+		if opts.Isolation != ISOLATION_LEVEL_REPEATABLE_READ {
+			return nil, errors.New("AtSnapshotNumber needs ISOLATION_LEVEL_REPEATABLE_READ")
+		}
+		tpb = append(tpb, byte(isc_tpb_at_snapshot_number), 8)
+		tpb = append(tpb, int32_to_bytes(int32(opts.AtSnapshotNumber))...)
+		tpb = append(tpb, int32_to_bytes(int32(opts.AtSnapshotNumber>>32))...)
+	}
+
+	for _, r := range opts.Reservations {
+		name := []byte(r.Table)
+		if len(name) == 0 || len(name) > 255 {
+			return nil, errors.New("Invalid table name for reservation")
+		}
+		if r.Mode != RESERVATION_SHARED && r.Mode != RESERVATION_PROTECTED && r.Mode != RESERVATION_EXCLUSIVE {
+			return nil, errors.New("Invalid table reservation mode")
 		}
-	case ISOLATION_LEVEL_READ_COMMITED_RO:
-		tpb = []byte{
-			byte(isc_tpb_version3),
-			byte(isc_tpb_read),
-			byte(isc_tpb_wait),
-			byte(isc_tpb_read_committed),
-			byte(isc_tpb_rec_version),
+		if r.Write {
+			tpb = append(tpb, byte(isc_tpb_lock_write))
+		} else {
+			tpb = append(tpb, byte(isc_tpb_lock_read))
 		}
+		tpb = append(tpb, byte(len(name)))
+		tpb = append(tpb, name...)
+		tpb = append(tpb, byte(r.Mode))
+	}
+
+	if opts.NoAutoUndo {
+		tpb = append(tpb, byte(isc_tpb_no_auto_undo))
+	}
+	if opts.IgnoreLimbo {
+		tpb = append(tpb, byte(isc_tpb_ignore_limbo))
+	}
+	if opts.AutoCommit {
+		tpb = append(tpb, byte(isc_tpb_autocommit))
+	}
+
+	return tpb, nil
+}
+
+type transactionOptionsKey struct{}
+
+// WithTransactionOptions returns a context that makes BeginTx start the transaction with opts.
+// A non default sql.TxOptions.Isolation and sql.TxOptions.ReadOnly override opts.
+func WithTransactionOptions(ctx context.Context, opts TransactionOptions) context.Context {
+	return context.WithValue(ctx, transactionOptionsKey{}, opts)
+}
+
+func transactionOptionsFromContext(ctx context.Context) (TransactionOptions, bool) {
+	opts, ok := ctx.Value(transactionOptionsKey{}).(TransactionOptions)
+	return opts, ok
+}
+
+func parseTransactionOptions(options map[string]string) (opts TransactionOptions, err error) {
+	opts = defaultTransactionOptions()
+
+	switch strings.ToLower(options["transaction_isolation"]) {
+	case "", "read_committed":
+		opts.Isolation = ISOLATION_LEVEL_READ_COMMITED
+	case "read_committed_legacy":
+		opts.Isolation = ISOLATION_LEVEL_READ_COMMITED_LEGACY
+	case "read_consistency":
+		opts.Isolation = ISOLATION_LEVEL_READ_CONSISTENCY
+	case "repeatable_read", "snapshot", "concurrency":
+		opts.Isolation = ISOLATION_LEVEL_REPEATABLE_READ
+	case "serializable", "snapshot_table_stability", "consistency":
+		opts.Isolation = ISOLATION_LEVEL_SERIALIZABLE
+	default:
+		err = errors.New("Invalid transaction_isolation:" + options["transaction_isolation"])
+		return
+	}
+
+	if options["transaction_lock_timeout"] != "" {
+		opts.LockTimeout, err = strconv.Atoi(options["transaction_lock_timeout"])
+		if err != nil || opts.LockTimeout < 0 {
+			err = errors.New("Invalid transaction_lock_timeout:" + options["transaction_lock_timeout"])
+			return
+		}
+	}
+
+	opts.ReadOnly = convertToBool(options["transaction_read_only"], false)
+	opts.NoWait = convertToBool(options["transaction_nowait"], false)
+	opts.NoAutoUndo = convertToBool(options["transaction_no_auto_undo"], false)
+	opts.IgnoreLimbo = convertToBool(options["transaction_ignore_limbo"], false)
+	opts.AutoCommit = convertToBool(options["transaction_auto_commit"], false)
+	opts.CommitRetaining = convertToBool(options["transaction_commit_retaining"], false)
+
+	return
+}
+
+type firebirdsqlTx struct {
+	fc           *firebirdsqlConn
+	opts         TransactionOptions
+	isAutocommit bool
+	isActive     bool
+	transHandle  int32
+}
+
+func (tx *firebirdsqlTx) begin() (err error) {
+	tpb, err := tx.opts.tpb()
+	if err != nil {
+		return
 	}
 	tx.fc.wp.opTransaction(tpb)
 	tx.transHandle, _, _, err = tx.fc.wp.opResponse()
+	if err != nil {
+		return
+	}
+	tx.isActive = true
 	tx.fc.transHandles = append(tx.fc.transHandles, tx.transHandle)
 	return
 }
 
-func (tx *firebirdsqlTx) Commit() (err error) {
-	tx.fc.wp.opCommitRetaining(tx.transHandle)
-	_, _, _, err = tx.fc.wp.opResponse()
+// beginIfNeeded starts a server transaction if the previous one was ended.
+func (tx *firebirdsqlTx) beginIfNeeded() (err error) {
+	if !tx.isActive {
+		err = tx.begin()
+	}
+	return
+}
+
+func (tx *firebirdsqlTx) end() {
+	for i, h := range tx.fc.transHandles {
+		if h == tx.transHandle {
+			tx.fc.transHandles = append(tx.fc.transHandles[:i], tx.fc.transHandles[i+1:]...)
+			break
+		}
+	}
+	tx.isActive = false
+}
+
+// reset makes tx the implicit transaction of the connection again.
+func (tx *firebirdsqlTx) reset() {
 	tx.isAutocommit = tx.fc.isAutocommit
+	if !tx.isActive {
+		tx.opts = tx.fc.txOptions
+	}
+}
+
+func (tx *firebirdsqlTx) commit(retaining bool) (err error) {
+	if !tx.isActive {
+		return
+	}
+	if retaining {
+		tx.fc.wp.opCommitRetaining(tx.transHandle)
+		_, _, _, err = tx.fc.wp.opResponse()
+		return
+	}
+	tx.fc.wp.opCommit(tx.transHandle)
+	_, _, _, err = tx.fc.wp.opResponse()
+	if err == nil {
+		tx.end()
+	}
 	return
 }
 
-func (tx *firebirdsqlTx) Rollback() (err error) {
-	tx.fc.wp.opRollbackRetaining(tx.transHandle)
+func (tx *firebirdsqlTx) info(items []byte) (map[int][]byte, error) {
+	err := tx.beginIfNeeded()
+	if err != nil {
+		return nil, err
+	}
+	tx.fc.wp.opInfoTransaction(tx.transHandle, append(items, isc_info_end))
+	_, _, buf, err := tx.fc.wp.opResponse()
+	if err != nil {
+		return nil, err
+	}
+	return parseInfoItems(buf)
+}
+
+// prepare is the first phase of two-phase commit.
+func (tx *firebirdsqlTx) prepare(message []byte) (err error) {
+	tx.fc.wp.opPrepare2(tx.transHandle, message)
 	_, _, _, err = tx.fc.wp.opResponse()
-	tx.isAutocommit = tx.fc.isAutocommit
 	return
 }
 
-func newFirebirdsqlTx(fc *firebirdsqlConn, isolationLevel int, isAutocommit bool) (tx *firebirdsqlTx, err error) {
+func (tx *firebirdsqlTx) rollback(retaining bool) (err error) {
+	if !tx.isActive {
+		return
+	}
+	if retaining {
+		tx.fc.wp.opRollbackRetaining(tx.transHandle)
+		_, _, _, err = tx.fc.wp.opResponse()
+		return
+	}
+	tx.fc.wp.opRollback(tx.transHandle)
+	_, _, _, err = tx.fc.wp.opResponse()
+	tx.end()
+	return
+}
+
+func (tx *firebirdsqlTx) Commit() (err error) {
+	err = tx.commit(tx.opts.CommitRetaining)
+	if err != nil && tx.isActive && !tx.opts.CommitRetaining {
+		// database/sql never rolls back a failed Commit, so do not leave it open.
+		tx.rollback(false)
+	}
+	tx.reset()
+	return
+}
+
+func (tx *firebirdsqlTx) Rollback() (err error) {
+	err = tx.rollback(tx.opts.CommitRetaining)
+	tx.reset()
+	return
+}
+
+func newFirebirdsqlTx(fc *firebirdsqlConn, opts TransactionOptions, isAutocommit bool) (tx *firebirdsqlTx, err error) {
 	tx = new(firebirdsqlTx)
 	tx.fc = fc
-	tx.isolationLevel = isolationLevel
+	tx.opts = opts
 	tx.isAutocommit = isAutocommit
-	tx.begin()
+	err = tx.begin()
+	return
+}
+
+// Tx wraps *sql.Tx with Firebird specific operations.
+type Tx struct {
+	*sql.Tx
+}
+
+func (tx Tx) savepointCommand(command string, name string) (err error) {
+	if name == "" {
+		return errors.New("Empty savepoint name")
+	}
+	_, err = tx.Exec(command + quoteIdentifier(name))
+	return
+}
+
+// Savepoint creates a savepoint, an existing one with the same name is replaced.
+func (tx Tx) Savepoint(name string) error {
+	return tx.savepointCommand("SAVEPOINT ", name)
+}
+
+// ReleaseSavepoint releases the savepoint and the savepoints created after it.
+func (tx Tx) ReleaseSavepoint(name string) error {
+	return tx.savepointCommand("RELEASE SAVEPOINT ", name)
+}
+
+// RollbackTo undoes the work done after the savepoint was created, the transaction stays active.
+func (tx Tx) RollbackTo(name string) error {
+	return tx.savepointCommand("ROLLBACK TO SAVEPOINT ", name)
+}
+
+// SnapshotNumber returns the snapshot number of the transaction active on conn
+// (started with conn.BeginTx or the implicit one). Firebird 4.0+
+//
+// Other connections can share the snapshot with TransactionOptions.AtSnapshotNumber.
+func SnapshotNumber(conn *sql.Conn) (n int64, err error) {
+	err = rawConn(conn, func(fc *firebirdsqlConn) error {
+		items, err := fc.tx.info([]byte{fb_info_tra_snapshot_number})
+		if err != nil {
+			return err
+		}
+		v, ok := items[fb_info_tra_snapshot_number]
+		if !ok {
+			return errors.New("Snapshot number is not available")
+		}
+		n = bytes_to_portable_int(v)
+		return nil
+	})
+	return
+}
+
+// TxInfo is the transaction information returned by TransactionInfo.
+type TxInfo struct {
+	ID                int64
+	Isolation         int // ISOLATION_LEVEL_*
+	ReadOnly          bool
+	LockTimeout       int // seconds, -1 means wait forever and 0 means no wait
+	OldestInteresting int64
+	OldestActive      int64
+	OldestSnapshot    int64
+	SnapshotNumber    int64 // Firebird 4.0+, 0 if not available
+}
+
+// TransactionInfo returns information about the transaction active on conn
+// (started with conn.BeginTx or the implicit one).
+// ID is the same as CURRENT_TRANSACTION and MON$TRANSACTION_ID.
+func TransactionInfo(conn *sql.Conn) (info *TxInfo, err error) {
+	err = rawConn(conn, func(fc *firebirdsqlConn) error {
+		items, err := fc.tx.info([]byte{
+			isc_info_tra_id,
+			isc_info_tra_isolation,
+			isc_info_tra_access,
+			isc_info_tra_lock_timeout,
+			isc_info_tra_oldest_interesting,
+			isc_info_tra_oldest_active,
+			isc_info_tra_oldest_snapshot,
+			fb_info_tra_snapshot_number,
+		})
+		if err != nil {
+			return err
+		}
+
+		info = &TxInfo{
+			ID:                bytes_to_portable_int(items[isc_info_tra_id]),
+			ReadOnly:          bytes_to_portable_int(items[isc_info_tra_access]) == isc_info_tra_readonly,
+			LockTimeout:       int(bytes_to_portable_int(items[isc_info_tra_lock_timeout])),
+			OldestInteresting: bytes_to_portable_int(items[isc_info_tra_oldest_interesting]),
+			OldestActive:      bytes_to_portable_int(items[isc_info_tra_oldest_active]),
+			OldestSnapshot:    bytes_to_portable_int(items[isc_info_tra_oldest_snapshot]),
+			SnapshotNumber:    bytes_to_portable_int(items[fb_info_tra_snapshot_number]),
+		}
+
+		isolation := items[isc_info_tra_isolation]
+		if len(isolation) == 0 {
+			return errors.New("Transaction isolation is not available")
+		}
+		switch isolation[0] {
+		case isc_info_tra_consistency:
+			info.Isolation = ISOLATION_LEVEL_SERIALIZABLE
+		case isc_info_tra_concurrency:
+			info.Isolation = ISOLATION_LEVEL_REPEATABLE_READ
+		case isc_info_tra_read_committed:
+			info.Isolation = ISOLATION_LEVEL_READ_COMMITED
+			if len(isolation) > 1 {
+				switch isolation[1] {
+				case isc_info_tra_no_rec_version:
+					info.Isolation = ISOLATION_LEVEL_READ_COMMITED_LEGACY
+				case isc_info_tra_read_consistency:
+					info.Isolation = ISOLATION_LEVEL_READ_CONSISTENCY
+				}
+			}
+		}
+		return nil
+	})
 	return
 }
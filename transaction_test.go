@@ -24,7 +24,12 @@ CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
 package firebirdsql
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -287,3 +292,507 @@ func TestIssue67(t *testing.T) {
 	}
 
 }
+
+func TestTransactionOptionsTpb(t *testing.T) {
+	var testTpbs = []struct {
+		opts TransactionOptions
+		tpb  []byte
+	}{
+		{
+			TransactionOptions{Isolation: ISOLATION_LEVEL_READ_COMMITED},
+			[]byte{isc_tpb_version3, isc_tpb_write, isc_tpb_wait, isc_tpb_read_committed, isc_tpb_rec_version},
+		},
+		{
+			TransactionOptions{Isolation: ISOLATION_LEVEL_REPEATABLE_READ, ReadOnly: true, NoWait: true},
+			[]byte{isc_tpb_version3, isc_tpb_read, isc_tpb_nowait, isc_tpb_concurrency},
+		},
+		{
+			TransactionOptions{Isolation: ISOLATION_LEVEL_READ_CONSISTENCY, LockTimeout: 5, NoAutoUndo: true},
+			[]byte{isc_tpb_version3, isc_tpb_write, isc_tpb_wait, isc_tpb_lock_timeout, 4, 5, 0, 0, 0, isc_tpb_read_committed, isc_tpb_read_consistency, isc_tpb_no_auto_undo},
+		},
+		{
+			TransactionOptions{
+				Isolation:    ISOLATION_LEVEL_SERIALIZABLE,
+				Reservations: []TableReservation{{"FOO", true, RESERVATION_PROTECTED}},
+				IgnoreLimbo:  true,
+				AutoCommit:   true,
+			},
+			[]byte{isc_tpb_version3, isc_tpb_write, isc_tpb_wait, isc_tpb_consistency, isc_tpb_lock_write, 3, 'F', 'O', 'O', isc_tpb_protected, isc_tpb_ignore_limbo, isc_tpb_autocommit},
+		},
+		{
+			TransactionOptions{Isolation: ISOLATION_LEVEL_REPEATABLE_READ, ReadOnly: true, AtSnapshotNumber: 0x102},
+			[]byte{isc_tpb_version3, isc_tpb_read, isc_tpb_wait, isc_tpb_concurrency, isc_tpb_at_snapshot_number, 8, 2, 1, 0, 0, 0, 0, 0, 0},
+		},
+	}
+
+	for _, d := range testTpbs {
+		tpb, err := d.opts.tpb()
+		if err != nil {
+			t.Fatalf("Error tpb(): %v", err)
+		}
+		if !bytes.Equal(tpb, d.tpb) {
+			t.Errorf("tpb() fail:%v != %v", tpb, d.tpb)
+		}
+	}
+
+	_, err := (&TransactionOptions{Isolation: 99}).tpb()
+	if err == nil {
+		t.Fatalf("Error Not occured")
+	}
+	_, err = (&TransactionOptions{Reservations: []TableReservation{{"FOO", false, 0}}}).tpb()
+	if err == nil {
+		t.Fatalf("Error Not occured")
+	}
+	_, err = (&TransactionOptions{Isolation: ISOLATION_LEVEL_READ_COMMITED, AtSnapshotNumber: 1}).tpb()
+	if err == nil {
+		t.Fatalf("Error Not occured")
+	}
+}
+
+func TestTransactionOptionsDSN(t *testing.T) {
+	_, _, _, _, options, err := parseDSN("user:password@localhost/dbname?transaction_isolation=snapshot&transaction_nowait=true&transaction_lock_timeout=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := parseTransactionOptions(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Isolation != ISOLATION_LEVEL_REPEATABLE_READ || !opts.NoWait || opts.LockTimeout != 3 || opts.ReadOnly {
+		t.Fatalf("parse transaction options fail:%v", opts)
+	}
+
+	_, _, _, _, options, _ = parseDSN("user:password@localhost/dbname?transaction_isolation=unknown")
+	_, err = parseTransactionOptions(options)
+	if err == nil {
+		t.Fatalf("Error Not occured")
+	}
+}
+
+func TestTransactionOptions(t *testing.T) {
+	temppath := TempFileName("test_transaction_options_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_trans_opts (s varchar(2048))")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath+"?transaction_nowait=true")
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+
+	ctx := WithTransactionOptions(context.Background(), TransactionOptions{
+		Isolation:    ISOLATION_LEVEL_REPEATABLE_READ,
+		NoWait:       true,
+		Reservations: []TableReservation{{"TEST_TRANS_OPTS", true, RESERVATION_PROTECTED}},
+	})
+	tx1, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("Error BeginTx: %v", err)
+	}
+	_, err = tx1.Exec("INSERT INTO test_trans_opts (s) values ('A')")
+	if err != nil {
+		t.Fatalf("Error Insert: %v", err)
+	}
+
+	tx2, err := conn.BeginTx(ctx, nil)
+	if err == nil {
+		tx2.Rollback()
+		t.Fatalf("Error lock conflict is not occured")
+	} else if !strings.Contains(err.Error(), "lock conflict") {
+		t.Fatalf("Need lock conflict error:%v", err)
+	}
+
+	err = tx1.Commit()
+	if err != nil {
+		t.Fatalf("Error Commit: %v", err)
+	}
+}
+
+func TestHardCommit(t *testing.T) {
+	temppath := TempFileName("test_hard_commit_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_hard_commit (s varchar(2048))")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	for _, params := range []string{"", "?transaction_commit_retaining=true"} {
+		db, err := sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath+params)
+		if err != nil {
+			t.Fatalf("Error sql.Open(): %v", err)
+		}
+		ctx := context.Background()
+		c, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("Error Conn(): %v", err)
+		}
+
+		var prev, id int64
+		for i := 0; i < 10; i++ {
+			tx, err := c.BeginTx(ctx, nil)
+			if err != nil {
+				t.Fatalf("Error BeginTx: %v", err)
+			}
+			_, err = tx.Exec("INSERT INTO test_hard_commit (s) values ('A')")
+			if err != nil {
+				t.Fatalf("Error Insert: %v", err)
+			}
+			err = tx.QueryRow("SELECT CURRENT_TRANSACTION FROM rdb$database").Scan(&id)
+			if err != nil {
+				t.Fatalf("Error SELECT: %v", err)
+			}
+			if id == prev {
+				t.Fatalf("Transaction is not renewed: %v", id)
+			}
+			prev = id
+			if i%2 == 0 {
+				err = tx.Commit()
+			} else {
+				err = tx.Rollback()
+			}
+			if err != nil {
+				t.Fatalf("Error Commit/Rollback: %v", err)
+			}
+		}
+
+		c.Raw(func(driverConn interface{}) error {
+			fc := driverConn.(*firebirdsqlConn)
+			if len(fc.transHandles) > 1 {
+				t.Fatalf("Transaction handles are not released: %v", fc.transHandles)
+			}
+			return nil
+		})
+
+		var n int
+		err = c.QueryRowContext(ctx, "SELECT Count(*) FROM test_hard_commit").Scan(&n)
+		if err != nil {
+			t.Fatalf("Error SELECT: %v", err)
+		}
+		if n%5 != 0 {
+			t.Fatalf("Incorrect count: %v", n)
+		}
+		c.Close()
+		db.Close()
+	}
+}
+
+func TestSavepoint(t *testing.T) {
+	temppath := TempFileName("test_savepoint_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_savepoint (s varchar(2048))")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+
+	sqlTx, err := conn.Begin()
+	if err != nil {
+		t.Fatalf("Error Begin: %v", err)
+	}
+	tx := Tx{sqlTx}
+	tx.Exec("INSERT INTO test_savepoint (s) values ('A')")
+	if err = tx.Savepoint("sp1"); err != nil {
+		t.Fatalf("Error Savepoint: %v", err)
+	}
+	tx.Exec("INSERT INTO test_savepoint (s) values ('B')")
+	if err = tx.Savepoint("sp2"); err != nil {
+		t.Fatalf("Error Savepoint: %v", err)
+	}
+	tx.Exec("INSERT INTO test_savepoint (s) values ('C')")
+	if err = tx.RollbackTo("sp2"); err != nil {
+		t.Fatalf("Error RollbackTo: %v", err)
+	}
+	if err = tx.ReleaseSavepoint("sp1"); err != nil {
+		t.Fatalf("Error ReleaseSavepoint: %v", err)
+	}
+	if err = tx.RollbackTo("sp1"); err == nil {
+		t.Fatalf("Error released savepoint is still available")
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("Error Commit: %v", err)
+	}
+
+	var n int
+	err = conn.QueryRow("SELECT Count(*) FROM test_savepoint").Scan(&n)
+	if err != nil {
+		t.Fatalf("Error SELECT: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("Incorrect count: %v", n)
+	}
+}
+
+func TestDistributedTransaction(t *testing.T) {
+	ctx := context.Background()
+	var dbs []*sql.DB
+	var conns []*sql.Conn
+	for i := 0; i < 2; i++ {
+		temppath := TempFileName("test_distributed_")
+		conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+		if err != nil {
+			t.Fatalf("Error sql.Open(): %v", err)
+		}
+		conn.Exec("CREATE TABLE test_distributed (s varchar(2048))")
+		conn.Close()
+
+		time.Sleep(1 * time.Second)
+
+		db, err := sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+		if err != nil {
+			t.Fatalf("Error sql.Open(): %v", err)
+		}
+		defer db.Close()
+		c, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("Error Conn(): %v", err)
+		}
+		defer c.Close()
+		dbs = append(dbs, db)
+		conns = append(conns, c)
+	}
+
+	// Rollback
+	dtx, err := BeginDistributed(conns, TransactionOptions{Isolation: ISOLATION_LEVEL_REPEATABLE_READ})
+	if err != nil {
+		t.Fatalf("Error BeginDistributed: %v", err)
+	}
+	for _, c := range conns {
+		_, err = c.ExecContext(ctx, "INSERT INTO test_distributed (s) values ('A')")
+		if err != nil {
+			t.Fatalf("Error Insert: %v", err)
+		}
+	}
+	if err = dtx.Rollback(); err != nil {
+		t.Fatalf("Error Rollback: %v", err)
+	}
+
+	// Prepare and Commit
+	dtx, err = BeginDistributed(conns, TransactionOptions{Isolation: ISOLATION_LEVEL_REPEATABLE_READ})
+	if err != nil {
+		t.Fatalf("Error BeginDistributed: %v", err)
+	}
+	for _, c := range conns {
+		_, err = c.ExecContext(ctx, "INSERT INTO test_distributed (s) values ('B')")
+		if err != nil {
+			t.Fatalf("Error Insert: %v", err)
+		}
+	}
+	if err = dtx.Prepare([]byte("test_distributed")); err != nil {
+		t.Fatalf("Error Prepare: %v", err)
+	}
+	if err = dtx.Commit(); err != nil {
+		t.Fatalf("Error Commit: %v", err)
+	}
+	if err = dtx.Commit(); err != sql.ErrTxDone {
+		t.Fatalf("Need ErrTxDone: %v", err)
+	}
+
+	for _, db := range dbs {
+		var s string
+		var n int
+		err = db.QueryRow("SELECT max(s), Count(*) FROM test_distributed").Scan(&s, &n)
+		if err != nil {
+			t.Fatalf("Error SELECT: %v", err)
+		}
+		if s != "B" || n != 1 {
+			t.Fatalf("Incorrect rows: %v %v", s, n)
+		}
+	}
+}
+
+func TestSnapshotNumber(t *testing.T) {
+	temppath := TempFileName("test_snapshot_number_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_snapshot_number (s varchar(2048))")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	c1, err := conn.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Error Conn: %v", err)
+	}
+	defer c1.Close()
+	tx1, err := c1.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		t.Fatalf("Error BeginTx: %v", err)
+	}
+	defer tx1.Rollback()
+	n, err := SnapshotNumber(c1)
+	if err != nil {
+		t.Fatalf("Error SnapshotNumber: %v", err)
+	}
+
+	_, err = conn.Exec("INSERT INTO test_snapshot_number (s) values ('A')")
+	if err != nil {
+		t.Fatalf("Error Insert: %v", err)
+	}
+
+	c2, err := conn.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Error Conn: %v", err)
+	}
+	defer c2.Close()
+	tx2, err := c2.BeginTx(WithTransactionOptions(ctx, TransactionOptions{
+		Isolation:        ISOLATION_LEVEL_REPEATABLE_READ,
+		ReadOnly:         true,
+		AtSnapshotNumber: n,
+	}), nil)
+	if err != nil {
+		t.Fatalf("Error BeginTx: %v", err)
+	}
+	defer tx2.Rollback()
+	m, err := SnapshotNumber(c2)
+	if err != nil {
+		t.Fatalf("Error SnapshotNumber: %v", err)
+	}
+	if m != n {
+		t.Fatalf("Snapshot is not shared: %v != %v", m, n)
+	}
+
+	var cnt int
+	err = tx2.QueryRow("SELECT Count(*) FROM test_snapshot_number").Scan(&cnt)
+	if err != nil {
+		t.Fatalf("Error SELECT: %v", err)
+	}
+	if cnt != 0 {
+		t.Fatalf("Incorrect count: %v", cnt)
+	}
+}
+
+func TestTransactionInfo(t *testing.T) {
+	temppath := TempFileName("test_transaction_info_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	c, err := conn.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Error Conn(): %v", err)
+	}
+	defer c.Close()
+	tx, err := c.BeginTx(WithTransactionOptions(ctx, TransactionOptions{
+		Isolation:   ISOLATION_LEVEL_REPEATABLE_READ,
+		ReadOnly:    true,
+		LockTimeout: 7,
+	}), nil)
+	if err != nil {
+		t.Fatalf("Error BeginTx: %v", err)
+	}
+	defer tx.Rollback()
+
+	info, err := TransactionInfo(c)
+	if err != nil {
+		t.Fatalf("Error TransactionInfo: %v", err)
+	}
+	var id int64
+	err = tx.QueryRow("SELECT CURRENT_TRANSACTION FROM rdb$database").Scan(&id)
+	if err != nil {
+		t.Fatalf("Error SELECT: %v", err)
+	}
+	if info.ID != id {
+		t.Fatalf("Incorrect transaction id: %v != %v", info.ID, id)
+	}
+	if info.Isolation != ISOLATION_LEVEL_REPEATABLE_READ || !info.ReadOnly || info.LockTimeout != 7 {
+		t.Fatalf("Incorrect transaction info: %v", info)
+	}
+	if info.OldestActive > info.ID || info.OldestInteresting > info.OldestActive {
+		t.Fatalf("Incorrect oldest transactions: %v", info)
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	if IsTransientError(errors.New("lock conflict on no wait transaction")) {
+		t.Errorf("plain error must not be transient")
+	}
+	err := &FbError{GDSCodes: []int{isc_lock_conflict, isc_update_conflict}, Message: "lock conflict on no wait transaction\nupdate conflicts with concurrent update\n"}
+	if !IsTransientError(fmt.Errorf("wrapped: %w", err)) {
+		t.Errorf("update conflict must be transient")
+	}
+	if IsTransientError(&FbError{GDSCodes: []int{335544665}, SQLCode: -803}) {
+		t.Errorf("unique key violation must not be transient")
+	}
+}
+
+func TestRunInTx(t *testing.T) {
+	temppath := TempFileName("test_run_in_tx_")
+	conn, err := sql.Open("firebirdsql_createdb", "sysdba:masterkey@localhost:3050"+temppath)
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	conn.Exec("CREATE TABLE test_run_in_tx (id integer, n integer)")
+	conn.Exec("INSERT INTO test_run_in_tx (id, n) values (1, 0)")
+	conn.Close()
+
+	time.Sleep(1 * time.Second)
+
+	conn, err = sql.Open("firebirdsql", "sysdba:masterkey@localhost:3050"+temppath+"?transaction_nowait=true")
+	if err != nil {
+		t.Fatalf("Error sql.Open(): %v", err)
+	}
+	defer conn.Close()
+
+	tx1, err := conn.Begin()
+	if err != nil {
+		t.Fatalf("Error Begin: %v", err)
+	}
+	_, err = tx1.Exec("UPDATE test_run_in_tx SET n = n + 1 WHERE id = 1")
+	if err != nil {
+		t.Fatalf("Error Update: %v", err)
+	}
+
+	attempts := 0
+	err = RunInTx(context.Background(), conn, nil, func(tx *sql.Tx) error {
+		attempts++
+		_, err := tx.Exec("UPDATE test_run_in_tx SET n = n + 1 WHERE id = 1")
+		if err != nil && attempts == 1 {
+			if !IsTransientError(err) {
+				t.Errorf("Need transient error: %v", err)
+			}
+			tx1.Commit()
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Error RunInTx: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("Incorrect attempts: %v", attempts)
+	}
+
+	var n int
+	conn.QueryRow("SELECT n FROM test_run_in_tx WHERE id = 1").Scan(&n)
+	if n != 2 {
+		t.Fatalf("Incorrect n: %v", n)
+	}
+}
@@ -28,11 +28,14 @@ import (
 	"container/list"
 	"encoding/binary"
 	"errors"
+	"math"
 	"math/big"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 func str_to_bytes(s string) []byte {
@@ -59,6 +62,12 @@ func bint32_to_bytes(i32 int32) []byte {
 	return bs
 }
 
+func bint64_to_bytes(i64 int64) []byte {
+	bs := make([]byte, 8)
+	binary.BigEndian.PutUint64(bs, uint64(i64))
+	return bs
+}
+
 func int16_to_bytes(i16 int16) []byte {
 	bs := []byte{
 		byte(i16 & 0xFF),
@@ -94,6 +103,46 @@ func bytes_to_int64(b []byte) int64 {
 	return int64(binary.LittleEndian.Uint64(b))
 }
 
+// bytes_to_portable_int decodes a little endian integer of 1 to 8 bytes (isc_portable_integer).
+func bytes_to_portable_int(b []byte) int64 {
+	var v int64
+	for i := len(b) - 1; i >= 0; i-- {
+		v = v<<8 | int64(b[i])
+	}
+	if len(b) > 0 && len(b) < 8 && b[len(b)-1]&0x80 != 0 {
+		v -= 1 << uint(8*len(b))
+	}
+	return v
+}
+
+// parseInfoItems splits an info response buffer (item, 2 bytes length, value ...) into values by item.
+// Unknown items are reported by the server as an isc_info_error item.
+func parseInfoItems(buf []byte) (map[int][]byte, error) {
+	items := make(map[int][]byte)
+	i := 0
+	for i < len(buf) {
+		item := int(buf[i])
+		i++
+		switch item {
+		case isc_info_end:
+			return items, nil
+		case isc_info_truncated:
+			return items, errors.New("Info buffer truncated")
+		}
+		if i+2 > len(buf) {
+			return items, errors.New("Invalid info buffer")
+		}
+		ln := int(bytes_to_int16(buf[i : i+2]))
+		i += 2
+		if i+ln > len(buf) {
+			return items, errors.New("Invalid info buffer")
+		}
+		items[item] = buf[i : i+ln]
+		i += ln
+	}
+	return items, nil
+}
+
 func bigFromHexString(s string) *big.Int {
 	ret := new(big.Int)
 	ret.SetString(s, 16)
@@ -173,6 +222,11 @@ func xdrString(s string) []byte {
 	return xdrBytes(bs)
 }
 
+// xdrPadded pads b to a multiple of 4 bytes.
+func xdrPadded(b []byte) []byte {
+	return append(b, make([]byte, (4-len(b))&3)...)
+}
+
 func _int32ToBlr(i32 int32) ([]byte, []byte) {
 	v := bytes.Join([][]byte{
 		bint32_to_bytes(i32),
@@ -182,6 +236,47 @@ func _int32ToBlr(i32 int32) ([]byte, []byte) {
 	return blr, v
 }
 
+func _int64ToBlr(i64 int64) ([]byte, []byte) {
+	if i64 >= math.MinInt32 && i64 <= math.MaxInt32 {
+		return _int32ToBlr(int32(i64))
+	}
+	blr := []byte{16, 0}
+	return blr, bint64_to_bytes(i64)
+}
+
+// scaledInteger converts a numeric parameter to the integer representation
+// of NUMERIC/DECIMAL with scale, rounding extra digits.
+func scaledInteger(v interface{}, scale int) (*big.Int, bool) {
+	var d decimal.Decimal
+	var err error
+	switch t := v.(type) {
+	case int64:
+		d = decimal.New(t, 0)
+	case int:
+		d = decimal.New(int64(t), 0)
+	case int32:
+		d = decimal.New(int64(t), 0)
+	case int16:
+		d = decimal.New(int64(t), 0)
+	case float64:
+		d = decimal.NewFromFloat(t)
+	case float32:
+		d = decimal.NewFromFloat(float64(t))
+	case decimal.Decimal:
+		d = t
+	case string:
+		d, err = decimal.NewFromString(t)
+	case []byte:
+		d, err = decimal.NewFromString(string(t))
+	default:
+		return nil, false
+	}
+	if err != nil {
+		return nil, false
+	}
+	return d.Shift(int32(-scale)).Round(0).BigInt(), true
+}
+
 func _bytesToBlr(v []byte) ([]byte, []byte) {
 	nbytes := len(v)
 	pad_length := ((4 - nbytes) & 3)
@@ -278,11 +373,21 @@ func parseDSN(dsn string) (addr string, dbName string, user string, passwd strin
 	m, _ := url.ParseQuery(u.RawQuery)
 
 	var default_options = map[string]string{
-		"auth_plugin_name":     "Srp",
-		"column_name_to_lower": "false",
-		"role":                 "",
-		"timezone":             "",
-		"wire_crypt":           "true",
+		"auth_plugin_name":             "Srp",
+		"column_name_to_lower":         "false",
+		"role":                         "",
+		"timezone":                     "",
+		"wire_crypt":                   "true",
+		"transaction_isolation":        "read_committed",
+		"transaction_read_only":        "false",
+		"transaction_nowait":           "false",
+		"transaction_lock_timeout":     "0",
+		"transaction_no_auto_undo":     "false",
+		"transaction_ignore_limbo":     "false",
+		"transaction_auto_commit":      "false",
+		"transaction_commit_retaining": "false",
+		"statement_cache_size":         "0",
+		"fetch_size":                   "400",
 	}
 
 	for k, v := range default_options {
@@ -297,6 +402,22 @@ func parseDSN(dsn string) (addr string, dbName string, user string, passwd strin
 	return
 }
 
+// quoteIdentifier quotes name for use as a SQL identifier.
+// Regular identifiers are upper-cased as the server would do for unquoted names.
+func quoteIdentifier(name string) string {
+	regular := len(name) > 0
+	for i, c := range name {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || i > 0 && (c >= '0' && c <= '9' || c == '_' || c == '$')) {
+			regular = false
+			break
+		}
+	}
+	if regular {
+		name = strings.ToUpper(name)
+	}
+	return "\"" + strings.Replace(name, "\"", "\"\"", -1) + "\""
+}
+
 func convertToBool(s string, defaultValue bool) bool {
 	v, err := strconv.ParseBool(s)
 	if err != nil {
@@ -305,6 +426,21 @@ func convertToBool(s string, defaultValue bool) bool {
 	return v
 }
 
+// calcRowLength returns the approximate bytes of a row on the wire.
+func calcRowLength(xsqlda []xSQLVAR) int {
+	n := 1
+	for _, x := range xsqlda {
+		switch x.sqltype {
+		case SQL_TYPE_VARYING:
+			n += x.sqllen + 4
+		default:
+			n += x.ioLength()
+		}
+		n += 4 // null indicator, alignment
+	}
+	return n
+}
+
 func calcBlr(xsqlda []xSQLVAR) []byte {
 	// Calculate  BLR from XSQLVAR array.
 	ln := len(xsqlda) * 2
@@ -86,3 +86,57 @@ func TestDSNParse(t *testing.T) {
 	}
 
 }
+
+func TestQuoteIdentifier(t *testing.T) {
+	var testIdentifiers = []struct {
+		name   string
+		quoted string
+	}{
+		{"sp1", `"SP1"`},
+		{"Foo_Bar$", `"FOO_BAR$"`},
+		{"1st", `"1st"`},
+		{"mixed Case", `"mixed Case"`},
+		{`a"b`, `"a""b"`},
+	}
+
+	for _, d := range testIdentifiers {
+		if quoted := quoteIdentifier(d.name); quoted != d.quoted {
+			t.Errorf("quoteIdentifier fail:%s(%s != %s)", d.name, quoted, d.quoted)
+		}
+	}
+}
+
+func TestParseInfoItems(t *testing.T) {
+	buf := []byte{
+		isc_info_tra_id, 4, 0, 0x2a, 0, 0, 0,
+		fb_info_tra_snapshot_number, 8, 0, 0xff, 0xff, 0xff, 0xff, 1, 0, 0, 0,
+		isc_info_tra_lock_timeout, 2, 0, 0xff, 0xff,
+		isc_info_end,
+	}
+	items, err := parseInfoItems(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := bytes_to_portable_int(items[isc_info_tra_id]); v != 42 {
+		t.Errorf("parseInfoItems fail:%v != 42", v)
+	}
+	if v := bytes_to_portable_int(items[fb_info_tra_snapshot_number]); v != 0x1ffffffff {
+		t.Errorf("parseInfoItems fail:%v != 0x1ffffffff", v)
+	}
+	if v := bytes_to_portable_int(items[isc_info_tra_lock_timeout]); v != -1 {
+		t.Errorf("parseInfoItems fail:%v != -1", v)
+	}
+
+	items, err = parseInfoItems([]byte{isc_info_error, 5, 0, 12, 0xfe, 0x2c, 0x00, 0x14, isc_info_tra_id, 1, 0, 7, isc_info_end})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := items[fb_info_tra_snapshot_number]; ok || bytes_to_portable_int(items[isc_info_tra_id]) != 7 {
+		t.Errorf("parseInfoItems fail:%v", items)
+	}
+
+	_, err = parseInfoItems([]byte{isc_info_tra_id, 4, 0, 1, isc_info_truncated})
+	if err == nil {
+		t.Fatalf("Error Not occured")
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"net"
 	"os"
@@ -69,6 +70,22 @@ func _INFO_SQL_SELECT_DESCRIBE_VARS() []byte {
 	}
 }
 
+func _INFO_SQL_BIND_DESCRIBE_VARS() []byte {
+	return []byte{
+		isc_info_sql_bind,
+		isc_info_sql_describe_vars,
+		isc_info_sql_sqlda_seq,
+		isc_info_sql_type,
+		isc_info_sql_sub_type,
+		isc_info_sql_scale,
+		isc_info_sql_length,
+		isc_info_sql_null_ind,
+		isc_info_sql_field,
+		isc_info_sql_relation,
+		isc_info_sql_describe_end,
+	}
+}
+
 type wireChannel struct {
 	conn      net.Conn
 	reader    *bufio.Reader
@@ -141,6 +158,7 @@ type wireProtocol struct {
 	acceptArchitecture int32
 	acceptType         int32
 	lazyResponseCount  int
+	deferFlush         bool // packets are kept in the writer until flushPackets
 
 	pluginName string
 	user       string
@@ -254,11 +272,25 @@ func (p *wireProtocol) sendPackets() (written int, err error) {
 		}
 		written += n
 	}
-	p.conn.Flush()
+	if !p.deferFlush {
+		p.conn.Flush()
+	}
 	p.buf = make([]byte, 0, BUFFER_LEN)
 	return
 }
 
+// deferPackets makes the following requests be sent together by flushPackets,
+// so that requests which do not wait for each other cost one round trip.
+// Their responses are received in the order of the requests.
+func (p *wireProtocol) deferPackets() {
+	p.deferFlush = true
+}
+
+func (p *wireProtocol) flushPackets() error {
+	p.deferFlush = false
+	return p.conn.Flush()
+}
+
 func (p *wireProtocol) suspendBuffer() []byte {
 	p.debugPrint("\tsuspendBuffer():%v", p.buf)
 	buf := p.buf
@@ -272,6 +304,12 @@ func (p *wireProtocol) resumeBuffer(buf []byte) {
 }
 
 func (p *wireProtocol) recvPackets(n int) ([]byte, error) {
+	if p.deferFlush {
+		// the response needs the deferred requests
+		if err := p.flushPackets(); err != nil {
+			return make([]byte, n), err
+		}
+	}
 	buf := make([]byte, n)
 	var err error
 	read := 0
@@ -310,7 +348,7 @@ func (p *wireProtocol) _parse_status_vector() (*list.List, int, string, error) {
 		switch {
 		case n == isc_arg_gds:
 			b, err = p.recvPackets(4)
-			gds_code := int(bytes_to_bint32(b))
+			gds_code = int(bytes_to_bint32(b))
 			if gds_code != 0 {
 				gds_codes.PushBack(gds_code)
 				message += errmsgs[gds_code]
@@ -359,12 +397,20 @@ func (p *wireProtocol) _parse_op_response() (int32, []byte, []byte, error) {
 
 	gds_code_list, sql_code, message, err := p._parse_status_vector()
 	if gds_code_list.Len() > 0 || sql_code != 0 {
-		err = errors.New(message)
+		err = newFbError(gds_code_list, sql_code, message)
 	}
 
 	return h, oid, buf, err
 }
 
+func newFbError(gds_code_list *list.List, sql_code int, message string) *FbError {
+	fbErr := &FbError{SQLCode: sql_code, Message: message}
+	for e := gds_code_list.Front(); e != nil; e = e.Next() {
+		fbErr.GDSCodes = append(fbErr.GDSCodes, e.Value.(int))
+	}
+	return fbErr
+}
+
 func (p *wireProtocol) _parse_connect_response(user string, password string, options map[string]string, clientPublic *big.Int, clientSecret *big.Int) (err error) {
 	p.debugPrint("_parse_connect_response")
 	wire_crypt := true
@@ -470,124 +516,165 @@ func (p *wireProtocol) _parse_connect_response(user string, password string, opt
 }
 
 
-func (p *wireProtocol) _parse_select_items(buf []byte, xsqlda []xSQLVAR) (int, error) {
-	var err error
+func (p *wireProtocol) _parse_select_items(buf []byte, xsqlda []xSQLVAR) (int, int, error) {
 	var ln int
 	index := 0
 	i := 0
-	for item := int(buf[i]); item != isc_info_end; item = int(buf[i]) {
+	for i < len(buf) {
+		item := int(buf[i])
+		switch item {
+		case isc_info_end, isc_info_sql_select, isc_info_sql_bind:
+			return -1, i, nil // no more info
+		case isc_info_truncated:
+			return index, i, nil // return next index
+		case isc_info_sql_describe_end:
+			i++
+			continue
+		}
 		i++
+		ln = int(bytes_to_int16(buf[i : i+2]))
+		i += 2
 		switch item {
 		case isc_info_sql_sqlda_seq:
-			ln = int(bytes_to_int16(buf[i : i+2]))
-			i += 2
 			index = int(bytes_to_int32(buf[i : i+ln]))
-			i += ln
 		case isc_info_sql_type:
-			ln = int(bytes_to_int16(buf[i : i+2]))
-			i += 2
 			sqltype := int(bytes_to_int32(buf[i : i+ln]))
 			if sqltype%2 != 0 {
 				sqltype--
 			}
 			xsqlda[index-1].sqltype = sqltype
-			i += ln
 		case isc_info_sql_sub_type:
-			ln = int(bytes_to_int16(buf[i : i+2]))
-			i += 2
 			xsqlda[index-1].sqlsubtype = int(bytes_to_int32(buf[i : i+ln]))
-			i += ln
 		case isc_info_sql_scale:
-			ln = int(bytes_to_int16(buf[i : i+2]))
-			i += 2
 			xsqlda[index-1].sqlscale = int(bytes_to_int32(buf[i : i+ln]))
-			i += ln
 		case isc_info_sql_length:
-			ln = int(bytes_to_int16(buf[i : i+2]))
-			i += 2
 			xsqlda[index-1].sqllen = int(bytes_to_int32(buf[i : i+ln]))
-			i += ln
 		case isc_info_sql_null_ind:
-			ln = int(bytes_to_int16(buf[i : i+2]))
-			i += 2
 			xsqlda[index-1].null_ok = bytes_to_int32(buf[i:i+ln]) != 0
-			i += ln
 		case isc_info_sql_field:
-			ln = int(bytes_to_int16(buf[i : i+2]))
-			i += 2
 			xsqlda[index-1].fieldname = bytes_to_str(buf[i : i+ln])
-			i += ln
 		case isc_info_sql_relation:
-			ln = int(bytes_to_int16(buf[i : i+2]))
-			i += 2
 			xsqlda[index-1].relname = bytes_to_str(buf[i : i+ln])
-			i += ln
 		case isc_info_sql_owner:
-			ln = int(bytes_to_int16(buf[i : i+2]))
-			i += 2
 			xsqlda[index-1].ownname = bytes_to_str(buf[i : i+ln])
-			i += ln
 		case isc_info_sql_alias:
-			ln = int(bytes_to_int16(buf[i : i+2]))
-			i += 2
 			xsqlda[index-1].aliasname = bytes_to_str(buf[i : i+ln])
-			i += ln
-		case isc_info_truncated:
-			return index, err // return next index
-		case isc_info_sql_describe_end:
-			/* NOTHING */
 		default:
-			err = errors.New(fmt.Sprintf("Invalid item [%02x] ! i=%d", buf[i], i))
-			break
+			return -1, i, errors.New(fmt.Sprintf("Invalid item [%02x] ! i=%d", item, i-3))
 		}
+		i += ln
 	}
-	return -1, err // no more info
+	return -1, i, nil
 }
 
-func (p *wireProtocol) parse_xsqlda(buf []byte, stmtHandle int32) (int32, []xSQLVAR, error) {
-	var ln, col_len, next_index int
-	var err error
-	var stmt_type int32
-	var xsqlda []xSQLVAR
-	i := 0
+// parse_describe_vars parses isc_info_sql_select or isc_info_sql_bind block at the top of buf.
+// When the buffer is truncated, the rest of variables are requested by opInfoSql
+// and truncated is set because no more items follow in buf.
+func (p *wireProtocol) parse_describe_vars(buf []byte, stmtHandle int32) (xsqlda []xSQLVAR, consumed int, truncated bool, err error) {
+	var next_index int
+	items := _INFO_SQL_SELECT_DESCRIBE_VARS()
+	if buf[0] == isc_info_sql_bind {
+		items = _INFO_SQL_BIND_DESCRIBE_VARS()
+	}
 
-	for i < len(buf) {
-		if buf[i] == byte(isc_info_sql_stmt_type) && buf[i+1] == byte(0x04) && buf[i+2] == byte(0x00) {
-			i += 1
-			ln = int(bytes_to_int16(buf[i : i+2]))
-			i += 2
-			stmt_type = int32(bytes_to_int32(buf[i : i+ln]))
-			i += ln
-		} else if buf[i] == byte(isc_info_sql_select) && buf[i+1] == byte(isc_info_sql_describe_vars) {
-			i += 2
-			ln = int(bytes_to_int16(buf[i : i+2]))
-			i += 2
-			col_len = int(bytes_to_int32(buf[i : i+ln]))
-			xsqlda = make([]xSQLVAR, col_len)
-			next_index, err = p._parse_select_items(buf[i+ln:], xsqlda)
-			for next_index > 0 { // more describe vars
-				p.opInfoSql(stmtHandle,
-					bytes.Join([][]byte{
-						[]byte{isc_info_sql_sqlda_start, 2},
-						int16_to_bytes(int16(next_index)),
-						_INFO_SQL_SELECT_DESCRIBE_VARS(),
-					}, nil))
-
-				_, _, buf, err = p.opResponse()
-				// buf[:2] == []byte{0x04,0x07}
-				ln = int(bytes_to_int16(buf[2:4]))
-				// bytes_to_int(buf[4:4+l]) == col_len
-				next_index, err = p._parse_select_items(buf[4+ln:], xsqlda)
-			}
-		} else {
+	// buf[1] == isc_info_sql_describe_vars
+	ln := int(bytes_to_int16(buf[2:4]))
+	xsqlda = make([]xSQLVAR, bytes_to_int32(buf[4:4+ln]))
+	next_index, consumed, err = p._parse_select_items(buf[4+ln:], xsqlda)
+	consumed += 4 + ln
+	truncated = next_index > 0
+
+	for next_index > 0 && err == nil { // more describe vars
+		p.opInfoSql(stmtHandle,
+			bytes.Join([][]byte{
+				[]byte{isc_info_sql_sqlda_start, 2},
+				int16_to_bytes(int16(next_index)),
+				items,
+			}, nil), int32(BUFFER_LEN))
+
+		_, _, buf, err = p.opResponse()
+		if err != nil {
 			break
 		}
+		// buf[:2] == []byte{items[0], isc_info_sql_describe_vars}
+		ln = int(bytes_to_int16(buf[2:4]))
+		next_index, _, err = p._parse_select_items(buf[4+ln:], xsqlda)
 	}
 
-	for i, _ := range xsqlda {
+	for i := range xsqlda {
 		xsqlda[i].wp = p
 	}
-	return stmt_type, xsqlda, err
+	return
+}
+
+// parse_plan parses the reply of isc_info_sql_get_plan or isc_info_sql_explain_plan.
+func parse_plan(buf []byte, item byte) (plan string, truncated bool, err error) {
+	if len(buf) == 0 || buf[0] == isc_info_end {
+		return
+	}
+	switch buf[0] {
+	case isc_info_truncated:
+		truncated = true
+		return
+	case isc_info_error:
+		err = errors.New("Plan information is not supported by the server")
+		return
+	}
+	if buf[0] != item || len(buf) < 3 {
+		err = errors.New(fmt.Sprintf("Unexpected plan information: %v", buf[0]))
+		return
+	}
+	ln := int(uint16(bytes_to_int16(buf[1:3])))
+	if len(buf) < 3+ln {
+		truncated = true
+		return
+	}
+	plan = strings.TrimSpace(bytes_to_str(buf[3 : 3+ln]))
+	return
+}
+
+// parse_xsqlda parses the prepare response and returns statement type,
+// output (select) and input (bind) variables.
+func (p *wireProtocol) parse_xsqlda(buf []byte, stmtHandle int32) (stmtType int32, stmtFlags int32, xsqlda []xSQLVAR, bindXsqlda []xSQLVAR, err error) {
+	var ln, n int
+	var truncated bool
+	hasBind := false
+	i := 0
+
+	for i < len(buf) && !truncated && err == nil {
+		switch buf[i] {
+		case isc_info_sql_stmt_type:
+			ln = int(bytes_to_int16(buf[i+1 : i+3]))
+			stmtType = int32(bytes_to_int32(buf[i+3 : i+3+ln]))
+			i += 3 + ln
+		case isc_info_sql_stmt_flags:
+			ln = int(bytes_to_int16(buf[i+1 : i+3]))
+			stmtFlags = int32(bytes_to_portable_int(buf[i+3 : i+3+ln]))
+			i += 3 + ln
+		case isc_info_error: // unknown item
+			ln = int(bytes_to_int16(buf[i+1 : i+3]))
+			i += 3 + ln
+		case isc_info_sql_select:
+			xsqlda, n, truncated, err = p.parse_describe_vars(buf[i:], stmtHandle)
+			i += n
+		case isc_info_sql_bind:
+			bindXsqlda, n, truncated, err = p.parse_describe_vars(buf[i:], stmtHandle)
+			hasBind = true
+			i += n
+		default:
+			i = len(buf)
+		}
+	}
+
+	if err == nil && !hasBind {
+		// bind variables did not fit in the buffer
+		p.opInfoSql(stmtHandle, _INFO_SQL_BIND_DESCRIBE_VARS(), int32(BUFFER_LEN))
+		_, _, buf, err = p.opResponse()
+		if err == nil {
+			bindXsqlda, _, _, err = p.parse_describe_vars(buf, stmtHandle)
+		}
+	}
+	return
 }
 
 func (p *wireProtocol) getBlobSegments(blobId []byte, transHandle int32) ([]byte, error) {
@@ -635,6 +722,11 @@ func (p *wireProtocol) opConnect(dbName string, user string, password string, op
 		"ffff800b00000001000000000000000500000004", // 11, 1, 0, 5, 4
 		"ffff800c00000001000000000000000500000006", // 12, 1, 0, 5, 6
 		"ffff800d00000001000000000000000500000008", // 13, 1, 0, 5, 8
+		"ffff800e0000000100000000000000050000000a", // 14, 1, 0, 5, 10
+		"ffff800f0000000100000000000000050000000c", // 15, 1, 0, 5, 12
+		"ffff80100000000100000000000000050000000e", // 16, 1, 0, 5, 14
+		"ffff801100000001000000000000000500000010", // 17, 1, 0, 5, 16
+		"ffff801200000001000000000000000500000012", // 18, 1, 0, 5, 18
 	}
 	p.packInt(op_connect)
 	p.packInt(op_attach)
@@ -769,6 +861,14 @@ func (p *wireProtocol) opCommitRetaining(transHandle int32) {
 	p.sendPackets()
 }
 
+func (p *wireProtocol) opPrepare2(transHandle int32, message []byte) {
+	p.debugPrint("opPrepare2():%d", transHandle)
+	p.packInt(op_prepare2)
+	p.packInt(transHandle)
+	p.packBytes(message)
+	p.sendPackets()
+}
+
 func (p *wireProtocol) opRollback(transHandle int32) {
 	p.debugPrint("opRollback():%d", transHandle)
 	p.packInt(op_rollback)
@@ -821,9 +921,14 @@ func (p *wireProtocol) opFreeStatement(stmtHandle int32, mode int32) {
 func (p *wireProtocol) opPrepareStatement(stmtHandle int32, transHandle int32, query string) {
 	p.debugPrint("opPrepareStatement():%d,%d,%v", transHandle, stmtHandle, query)
 
+	items := []byte{isc_info_sql_stmt_type}
+	if p.protocolVersion >= PROTOCOL_VERSION16 {
+		items = append(items, isc_info_sql_stmt_flags)
+	}
 	bs := bytes.Join([][]byte{
-		[]byte{isc_info_sql_stmt_type},
+		items,
 		_INFO_SQL_SELECT_DESCRIBE_VARS(),
+		_INFO_SQL_BIND_DESCRIBE_VARS(),
 	}, nil)
 	p.packInt(op_prepare_statement)
 	p.packInt(transHandle)
@@ -835,49 +940,118 @@ func (p *wireProtocol) opPrepareStatement(stmtHandle int32, transHandle int32, q
 	p.sendPackets()
 }
 
-func (p *wireProtocol) opInfoSql(stmtHandle int32, vars []byte) {
+func (p *wireProtocol) opExecImmediate(transHandle int32, query string) {
+	p.debugPrint("opExecImmediate():%d,%v", transHandle, query)
+	p.packInt(op_execute_immediate)
+	p.packInt(transHandle)
+	p.packInt(0) // statement
+	p.packInt(3) // dialect = 3
+	p.packString(query)
+	p.packBytes([]byte{})
+	p.packInt(0) // buffer length
+	p.sendPackets()
+}
+
+func (p *wireProtocol) opSetCursor(stmtHandle int32, cursorName string) {
+	p.debugPrint("opSetCursor")
+	p.packInt(op_set_cursor)
+	p.packInt(stmtHandle)
+	p.packBytes(append([]byte(cursorName), 0))
+	p.packInt(0) // type
+	p.sendPackets()
+}
+
+func (p *wireProtocol) opBatchCreate(stmtHandle int32, blr []byte, msgLength int32, bpb []byte) {
+	p.debugPrint("opBatchCreate")
+	p.packInt(op_batch_create)
+	p.packInt(stmtHandle)
+	p.packBytes(blr)
+	p.packInt(msgLength)
+	p.packBytes(bpb)
+	p.sendPackets()
+}
+
+func (p *wireProtocol) opBatchMsg(stmtHandle int32, count int32, messages []byte) {
+	p.debugPrint("opBatchMsg")
+	p.packInt(op_batch_msg)
+	p.packInt(stmtHandle)
+	p.packInt(count)
+	p.appendBytes(messages)
+	p.sendPackets()
+}
+
+// opBatchBlobStream sends a portion of the blob stream, length is the size of the portion
+// on the server which includes the alignment of the blob headers.
+func (p *wireProtocol) opBatchBlobStream(stmtHandle int32, length int32, stream []byte) {
+	p.debugPrint("opBatchBlobStream")
+	p.packInt(op_batch_blob_stream)
+	p.packInt(stmtHandle)
+	p.packInt(length)
+	p.appendBytes(stream)
+	p.sendPackets()
+}
+
+func (p *wireProtocol) opBatchExec(stmtHandle int32, transHandle int32) {
+	p.debugPrint("opBatchExec")
+	p.packInt(op_batch_exec)
+	p.packInt(stmtHandle)
+	p.packInt(transHandle)
+	p.sendPackets()
+}
+
+func (p *wireProtocol) opBatchRls(stmtHandle int32) {
+	p.debugPrint("opBatchRls")
+	p.packInt(op_batch_rls)
+	p.packInt(stmtHandle)
+	p.sendPackets()
+}
+
+func (p *wireProtocol) opInfoSql(stmtHandle int32, vars []byte, bufferLength int32) {
 	p.debugPrint("opInfoSql")
 	p.packInt(op_info_sql)
 	p.packInt(stmtHandle)
 	p.packInt(0)
 	p.packBytes(vars)
-	p.packInt(int32(BUFFER_LEN))
+	p.packInt(bufferLength)
 	p.sendPackets()
 }
 
-func (p *wireProtocol) opExecute(stmtHandle int32, transHandle int32, params []driver.Value) {
-	p.debugPrint("opExecute():%d,%d,%v", transHandle, stmtHandle, params)
+func (p *wireProtocol) opExecute(stmtHandle int32, transHandle int32, blr []byte, values []byte, cursorFlags int32) {
+	p.debugPrint("opExecute():%d,%d", transHandle, stmtHandle)
 	p.packInt(op_execute)
 	p.packInt(stmtHandle)
 	p.packInt(transHandle)
 
-	if len(params) == 0 {
+	if len(blr) == 0 {
 		p.packInt(0) // packBytes([])
 		p.packInt(0)
 		p.packInt(0)
-		p.sendPackets()
 	} else {
-		blr, values := p.paramsToBlr(transHandle, params, p.protocolVersion)
 		p.packBytes(blr)
 		p.packInt(0)
 		p.packInt(1)
 		p.appendBytes(values)
-		p.sendPackets()
 	}
+	if p.protocolVersion >= PROTOCOL_VERSION16 {
+		p.packInt(0) // statement timeout
+	}
+	if p.protocolVersion >= PROTOCOL_VERSION18 {
+		p.packInt(cursorFlags)
+	}
+	p.sendPackets()
 }
 
-func (p *wireProtocol) opExecute2(stmtHandle int32, transHandle int32, params []driver.Value, outputBlr []byte) {
+func (p *wireProtocol) opExecute2(stmtHandle int32, transHandle int32, blr []byte, values []byte, outputBlr []byte) {
 	p.debugPrint("opExecute2")
 	p.packInt(op_execute2)
 	p.packInt(stmtHandle)
 	p.packInt(transHandle)
 
-	if len(params) == 0 {
+	if len(blr) == 0 {
 		p.packInt(0) // packBytes([])
 		p.packInt(0)
 		p.packInt(0)
 	} else {
-		blr, values := p.paramsToBlr(transHandle, params, p.protocolVersion)
 		p.packBytes(blr)
 		p.packInt(0)
 		p.packInt(1)
@@ -886,16 +1060,34 @@ func (p *wireProtocol) opExecute2(stmtHandle int32, transHandle int32, params []
 
 	p.packBytes(outputBlr)
 	p.packInt(0)
+	if p.protocolVersion >= PROTOCOL_VERSION16 {
+		p.packInt(0) // statement timeout
+	}
+	if p.protocolVersion >= PROTOCOL_VERSION18 {
+		p.packInt(0) // cursor flags
+	}
 	p.sendPackets()
 }
 
-func (p *wireProtocol) opFetch(stmtHandle int32, blr []byte) {
+func (p *wireProtocol) opFetch(stmtHandle int32, blr []byte, count int32) {
 	p.debugPrint("opFetch")
 	p.packInt(op_fetch)
 	p.packInt(stmtHandle)
 	p.packBytes(blr)
 	p.packInt(0)
-	p.packInt(400)
+	p.packInt(count)
+	p.sendPackets()
+}
+
+func (p *wireProtocol) opFetchScroll(stmtHandle int32, blr []byte, fetchOp int32, pos int32) {
+	p.debugPrint("opFetchScroll")
+	p.packInt(op_fetch_scroll)
+	p.packInt(stmtHandle)
+	p.packBytes(blr)
+	p.packInt(0)
+	p.packInt(1)
+	p.packInt(fetchOp)
+	p.packInt(pos)
 	p.sendPackets()
 }
 
@@ -1045,6 +1237,31 @@ func (p *wireProtocol) opBatchSegments(blobHandle int32, seg_data []byte) {
 	p.sendPackets()
 }
 
+func (p *wireProtocol) opGetSlice(transHandle int32, sliceId []byte, sliceLength int32, sdl []byte) {
+	p.debugPrint("opGetSlice")
+	p.packInt(op_get_slice)
+	p.packInt(transHandle)
+	p.appendBytes(sliceId)
+	p.packInt(sliceLength)
+	p.packBytes(sdl)
+	p.packInt(0) // parameters
+	p.packInt(0) // slice
+	p.sendPackets()
+}
+
+func (p *wireProtocol) opPutSlice(transHandle int32, sliceLength int32, sdl []byte, slice []byte) {
+	p.debugPrint("opPutSlice")
+	p.packInt(op_put_slice)
+	p.packInt(transHandle)
+	p.appendBytes(make([]byte, 8)) // new slice id
+	p.packInt(sliceLength)
+	p.packBytes(sdl)
+	p.packInt(0) // parameters
+	p.packInt(sliceLength)
+	p.appendBytes(slice)
+	p.sendPackets()
+}
+
 func (p *wireProtocol) opCloseBlob(blobHandle int32) {
 	p.debugPrint("opCloseBlob")
 	p.packInt(op_close_blob)
@@ -1073,6 +1290,91 @@ func (p *wireProtocol) opResponse() (int32, []byte, []byte, error) {
 	return p._parse_op_response()
 }
 
+// opBatchCsResponse receives the completion state of op_batch_exec.
+func (p *wireProtocol) opBatchCsResponse() (*BatchCompletionState, error) {
+	p.debugPrint("opBatchCsResponse")
+	b, err := p.recvPackets(4)
+	for bytes_to_bint32(b) == op_dummy {
+		b, err = p.recvPackets(4)
+	}
+	for bytes_to_bint32(b) == op_response && p.lazyResponseCount > 0 {
+		p.lazyResponseCount--
+		_, _, _, _ = p._parse_op_response()
+		b, err = p.recvPackets(4)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch bytes_to_bint32(b) {
+	case op_response:
+		_, _, _, err = p._parse_op_response()
+		if err == nil {
+			err = errors.New("Unexpected op_response for op_batch_exec")
+		}
+		return nil, err
+	case op_batch_cs:
+	default:
+		return nil, errors.New(fmt.Sprintf("Error op_batch_cs:%d", bytes_to_bint32(b)))
+	}
+
+	b, err = p.recvPackets(20)
+	if err != nil {
+		return nil, err
+	}
+	cs := &BatchCompletionState{RecordCount: int(bytes_to_bint32(b[4:8]))}
+	nUpdates := int(bytes_to_bint32(b[8:12]))
+	nVectors := int(bytes_to_bint32(b[12:16]))
+	nErrors := int(bytes_to_bint32(b[16:20]))
+
+	for i := 0; i < nUpdates && err == nil; i++ {
+		b, err = p.recvPackets(4)
+		cs.UpdateCounts = append(cs.UpdateCounts, int(bytes_to_bint32(b)))
+	}
+	for i := 0; i < nVectors && err == nil; i++ {
+		b, err = p.recvPackets(4)
+		record := int(bytes_to_bint32(b))
+		gds_code_list, sql_code, message, e := p._parse_status_vector()
+		err = e
+		cs.Errors = append(cs.Errors, BatchError{Record: record, Err: newFbError(gds_code_list, sql_code, message)})
+	}
+	for i := 0; i < nErrors && err == nil; i++ {
+		b, err = p.recvPackets(4)
+		cs.Errors = append(cs.Errors, BatchError{Record: int(bytes_to_bint32(b))})
+	}
+	return cs, err
+}
+
+// opSliceResponse receives the header of op_slice, the elements of the slice follow.
+func (p *wireProtocol) opSliceResponse() (sliceLength int32, err error) {
+	p.debugPrint("opSliceResponse")
+	b, err := p.recvPackets(4)
+	for bytes_to_bint32(b) == op_dummy {
+		b, err = p.recvPackets(4)
+	}
+	for bytes_to_bint32(b) == op_response && p.lazyResponseCount > 0 {
+		p.lazyResponseCount--
+		_, _, _, _ = p._parse_op_response()
+		b, err = p.recvPackets(4)
+	}
+	if err != nil {
+		return
+	}
+	switch bytes_to_bint32(b) {
+	case op_response:
+		_, _, _, err = p._parse_op_response()
+		if err == nil {
+			err = errors.New("Unexpected op_response for op_get_slice")
+		}
+		return
+	case op_slice:
+	default:
+		return 0, errors.New(fmt.Sprintf("Error op_slice:%d", bytes_to_bint32(b)))
+	}
+	// p_slr_length and the length of p_slr_slice
+	b, err = p.recvPackets(8)
+	return bytes_to_bint32(b[4:]), err
+}
+
 func (p *wireProtocol) opSqlResponse(xsqlda []xSQLVAR) ([]driver.Value, error) {
 	p.debugPrint("opSqlResponse")
 	b, err := p.recvPackets(4)
@@ -1080,6 +1382,13 @@ func (p *wireProtocol) opSqlResponse(xsqlda []xSQLVAR) ([]driver.Value, error) {
 		b, err = p.recvPackets(4)
 	}
 
+	if bytes_to_bint32(b) == op_response {
+		_, _, _, err = p._parse_op_response()
+		if err == nil {
+			err = errors.New("Error op_sql_response")
+		}
+		return nil, err
+	}
 	if bytes_to_bint32(b) != op_sql_response {
 		return nil, errors.New("Error op_sql_response")
 	}
@@ -1173,10 +1482,17 @@ func (p *wireProtocol) createBlob(value []byte, transHandle int32) ([]byte, erro
 	return blobId, err
 }
 
-func (p *wireProtocol) paramsToBlr(transHandle int32, params []driver.Value, protocolVersion int32) ([]byte, []byte) {
-	// Convert parameter array to BLR and values format.
+func (p *wireProtocol) paramsToBlr(transHandle int32, xsqlda []xSQLVAR, params []driver.Value, protocolVersion int32) ([]byte, []byte, error) {
+	// Convert parameter array to BLR and values format by the declared types.
 	var v, blr []byte
-	bi256 := big.NewInt(256)
+	var err error
+
+	if len(params) != len(xsqlda) {
+		return nil, nil, errors.New(fmt.Sprintf("Expected %d arguments, got %d", len(xsqlda), len(params)))
+	}
+	if len(params) == 0 {
+		return nil, nil, nil
+	}
 
 	ln := len(params) * 2
 	blrList := list.New()
@@ -1184,74 +1500,17 @@ func (p *wireProtocol) paramsToBlr(transHandle int32, params []driver.Value, pro
 	blrList.PushBack([]byte{5, 2, 4, 0, byte(ln & 255), byte(ln >> 8)})
 
 	if protocolVersion >= PROTOCOL_VERSION13 {
-		nullIndicator := new(big.Int)
-		for i := len(params) - 1; i >= 0; i-- {
-			if params[i] == nil {
-				nullIndicator.SetBit(nullIndicator, i, 1)
-			}
-		}
-		n := len(params) / 8
-		if len(params)%8 != 0 {
-			n++
-		}
-		if n%4 != 0 { // padding
-			n += 4 - n%4
-		}
-		for i := 0; i < n; i++ {
-			valuesList.PushBack([]byte{byte(nullIndicator.Mod(nullIndicator, bi256).Int64())})
-			nullIndicator = nullIndicator.Div(nullIndicator, bi256)
-		}
+		valuesList.PushBack(nullBitmap(params))
 	}
 
-	for _, param := range params {
-		switch f := param.(type) {
-		case string:
-			b := str_to_bytes(f)
-			if len(b) < MAX_CHAR_LENGTH {
-				blr, v = _bytesToBlr(b)
-			} else {
-				v, _ = p.createBlob(b, transHandle)
-				blr = []byte{9, 0}
-			}
-		case int:
-			blr, v = _int32ToBlr(int32(f))
-		case int16:
-			blr, v = _int32ToBlr(int32(f))
-		case int32:
-			blr, v = _int32ToBlr(f)
-		case int64:
-			blr, v = _int32ToBlr(int32(f))
-		case time.Time:
-			if f.Year() == 0 {
-				blr, v = _timeToBlr(f)
-			} else {
-				blr, v = _timestampToBlr(f)
-			}
-		case bool:
-			if f {
-				v = []byte{1, 0, 0, 0}
-			} else {
-				v = []byte{0, 0, 0, 0}
-			}
-			blr = []byte{23}
-		case nil:
+	for i, param := range params {
+		if param == nil {
 			v = []byte{}
 			blr = []byte{14, 0, 0}
-		case []byte:
-			if len(f) < MAX_CHAR_LENGTH {
-				blr, v = _bytesToBlr(f)
-			} else {
-				v, _ = p.createBlob(f, transHandle)
-				blr = []byte{9, 0}
-			}
-		default:
-			// can't convert directory
-			b := str_to_bytes(fmt.Sprintf("%v", f))
-			if len(b) < MAX_CHAR_LENGTH {
-				blr, v = _bytesToBlr(b)
-			} else {
-				v, _ = p.createBlob(b, transHandle)
-				blr = []byte{9, 0}
+		} else {
+			blr, v, err = p.paramToBlr(transHandle, &xsqlda[i], param)
+			if err != nil {
+				return nil, nil, errors.New(fmt.Sprintf("Parameter %d: %v", i+1, err))
 			}
 		}
 		valuesList.PushBack(v)
@@ -1270,7 +1529,157 @@ func (p *wireProtocol) paramsToBlr(transHandle int32, params []driver.Value, pro
 	blr = flattenBytes(blrList)
 	v = flattenBytes(valuesList)
 
-	return blr, v
+	return blr, v, nil
+}
+
+// nullBitmap returns the null indicator bitmap of a message (protocol 13 or later).
+func nullBitmap(params []driver.Value) []byte {
+	n := len(params) / 8
+	if len(params)%8 != 0 {
+		n++
+	}
+	if n%4 != 0 { // padding
+		n += 4 - n%4
+	}
+	bitmap := make([]byte, n)
+	for i, param := range params {
+		if param == nil {
+			bitmap[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return bitmap
+}
+
+// paramToBlr encodes a parameter as the declared type of x.
+// Types without a native encoding here are sent as the Go type suggests
+// and converted by the server.
+func (p *wireProtocol) paramToBlr(transHandle int32, x *xSQLVAR, param driver.Value) (blr []byte, v []byte, err error) {
+	switch x.sqltype {
+	case SQL_TYPE_SHORT, SQL_TYPE_LONG, SQL_TYPE_INT64:
+		n, ok := scaledInteger(param, x.sqlscale)
+		if !ok {
+			break
+		}
+		sqlscale := byte(x.sqlscale)
+		switch {
+		case x.sqltype == SQL_TYPE_SHORT && n.IsInt64() && n.Int64() >= math.MinInt16 && n.Int64() <= math.MaxInt16:
+			return []byte{7, sqlscale}, bint32_to_bytes(int32(n.Int64())), nil
+		case x.sqltype == SQL_TYPE_LONG && n.IsInt64() && n.Int64() >= math.MinInt32 && n.Int64() <= math.MaxInt32:
+			return []byte{8, sqlscale}, bint32_to_bytes(int32(n.Int64())), nil
+		case x.sqltype == SQL_TYPE_INT64 && n.IsInt64():
+			return []byte{16, sqlscale}, bint64_to_bytes(n.Int64()), nil
+		}
+		return nil, nil, errors.New(fmt.Sprintf("Value %v is out of range for %s", param, x.typename()))
+	case SQL_TYPE_FLOAT, SQL_TYPE_DOUBLE:
+		var f float64
+		switch t := param.(type) {
+		case float64:
+			f = t
+		case float32:
+			f = float64(t)
+		case int64:
+			f = float64(t)
+		case int:
+			f = float64(t)
+		default:
+			return p.valueToBlr(transHandle, param)
+		}
+		return []byte{27}, bint64_to_bytes(int64(math.Float64bits(f))), nil
+	case SQL_TYPE_DATE:
+		if t, ok := param.(time.Time); ok {
+			blr, v = _dateToBlr(t)
+			return
+		}
+	case SQL_TYPE_TIME:
+		if t, ok := param.(time.Time); ok {
+			blr, v = _timeToBlr(t)
+			return
+		}
+	case SQL_TYPE_TIMESTAMP:
+		if t, ok := param.(time.Time); ok {
+			blr, v = _timestampToBlr(t)
+			return
+		}
+	case SQL_TYPE_BOOLEAN:
+		if b, ok := param.(bool); ok {
+			if b {
+				v = []byte{1, 0, 0, 0}
+			} else {
+				v = []byte{0, 0, 0, 0}
+			}
+			return []byte{23}, v, nil
+		}
+	case SQL_TYPE_BLOB:
+		var b []byte
+		switch t := param.(type) {
+		case []byte:
+			b = t
+		case string:
+			b = str_to_bytes(t)
+		default:
+			b = str_to_bytes(fmt.Sprintf("%v", t))
+		}
+		v, err = p.createBlob(b, transHandle)
+		return []byte{9, 0}, v, err
+	case SQL_TYPE_ARRAY:
+		if id, ok := param.(arrayId); ok {
+			return []byte{9, 0}, id, nil
+		}
+		return nil, nil, errors.New(fmt.Sprintf("ARRAY parameter needs a slice, not %T", param))
+	}
+	return p.valueToBlr(transHandle, param)
+}
+
+// valueToBlr encodes a parameter as the Go type suggests.
+func (p *wireProtocol) valueToBlr(transHandle int32, param driver.Value) (blr []byte, v []byte, err error) {
+	switch f := param.(type) {
+	case string:
+		b := str_to_bytes(f)
+		if len(b) < MAX_CHAR_LENGTH {
+			blr, v = _bytesToBlr(b)
+		} else {
+			v, err = p.createBlob(b, transHandle)
+			blr = []byte{9, 0}
+		}
+	case int:
+		blr, v = _int64ToBlr(int64(f))
+	case int16:
+		blr, v = _int32ToBlr(int32(f))
+	case int32:
+		blr, v = _int32ToBlr(f)
+	case int64:
+		blr, v = _int64ToBlr(f)
+	case time.Time:
+		if f.Year() == 0 {
+			blr, v = _timeToBlr(f)
+		} else {
+			blr, v = _timestampToBlr(f)
+		}
+	case bool:
+		if f {
+			v = []byte{1, 0, 0, 0}
+		} else {
+			v = []byte{0, 0, 0, 0}
+		}
+		blr = []byte{23}
+	case []byte:
+		if len(f) < MAX_CHAR_LENGTH {
+			blr, v = _bytesToBlr(f)
+		} else {
+			v, err = p.createBlob(f, transHandle)
+			blr = []byte{9, 0}
+		}
+	default:
+		// can't convert directory
+		b := str_to_bytes(fmt.Sprintf("%v", f))
+		if len(b) < MAX_CHAR_LENGTH {
+			blr, v = _bytesToBlr(b)
+		} else {
+			v, err = p.createBlob(b, transHandle)
+			blr = []byte{9, 0}
+		}
+	}
+	return
 }
 
 func (p *wireProtocol) debugPrint(s string, a ...interface{}) {
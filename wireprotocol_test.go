@@ -0,0 +1,173 @@
+/*******************************************************************************
+The MIT License (MIT)
+
+Copyright (c) 2026 Hajime Nakagami
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+the Software, and to permit persons to whom the Software is furnished to do so,
+subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+*******************************************************************************/
+
+package firebirdsql
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"net"
+	"testing"
+)
+
+func TestParseXsqlda(t *testing.T) {
+	p := &wireProtocol{}
+	buf := []byte{
+		isc_info_sql_stmt_type, 4, 0, isc_info_sql_stmt_select, 0, 0, 0,
+		isc_info_sql_stmt_flags, 4, 0, stmt_flag_has_cursor, 0, 0, 0,
+		isc_info_error, 4, 0, 0, 0, 0, 0,
+		isc_info_sql_select, isc_info_sql_describe_vars, 4, 0, 1, 0, 0, 0,
+		isc_info_sql_sqlda_seq, 4, 0, 1, 0, 0, 0,
+		isc_info_sql_type, 4, 0, 0x45, 0x02, 0, 0, // SQL_TYPE_INT64 + 1
+		isc_info_sql_alias, 2, 0, 'I', 'D',
+		isc_info_sql_describe_end,
+		isc_info_sql_bind, isc_info_sql_describe_vars, 4, 0, 2, 0, 0, 0,
+		isc_info_sql_sqlda_seq, 4, 0, 1, 0, 0, 0,
+		isc_info_sql_type, 4, 0, 0xf0, 0x01, 0, 0, // SQL_TYPE_LONG
+		isc_info_sql_scale, 4, 0, 0xfe, 0xff, 0xff, 0xff,
+		isc_info_sql_describe_end,
+		isc_info_sql_sqlda_seq, 4, 0, 2, 0, 0, 0,
+		isc_info_sql_type, 4, 0, 0x3b, 0x02, 0, 0, // SQL_TYPE_DATE + 1
+		isc_info_sql_describe_end,
+		isc_info_end,
+	}
+	stmtType, stmtFlags, xsqlda, bindXsqlda, err := p.parse_xsqlda(buf, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmtType != isc_info_sql_stmt_select || stmtFlags != stmt_flag_has_cursor {
+		t.Errorf("Incorrect statement type:%v %v", stmtType, stmtFlags)
+	}
+	if len(xsqlda) != 1 || xsqlda[0].sqltype != SQL_TYPE_INT64 || xsqlda[0].aliasname != "ID" {
+		t.Errorf("Incorrect select xsqlda:%v", xsqlda)
+	}
+	if len(bindXsqlda) != 2 || bindXsqlda[0].sqltype != SQL_TYPE_LONG || bindXsqlda[0].sqlscale != -2 || bindXsqlda[1].sqltype != SQL_TYPE_DATE {
+		t.Errorf("Incorrect bind xsqlda:%v", bindXsqlda)
+	}
+}
+
+func TestParamsToBlr(t *testing.T) {
+	p := &wireProtocol{}
+	xsqlda := []xSQLVAR{
+		{sqltype: SQL_TYPE_INT64, sqlscale: -2},
+		{sqltype: SQL_TYPE_DOUBLE},
+		{sqltype: SQL_TYPE_BOOLEAN},
+		{sqltype: SQL_TYPE_VARYING, sqllen: 10},
+	}
+	blr, values, err := p.paramsToBlr(0, xsqlda, []driver.Value{"12.345", int64(3), true, nil}, PROTOCOL_VERSION13)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []byte{5, 2, 4, 0, 8, 0, 16, 0xfe, 7, 0, 27, 7, 0, 23, 7, 0, 14, 0, 0, 7, 0, 255, 76}
+	if !bytes.Equal(blr, expected) {
+		t.Errorf("Incorrect blr:%v", blr)
+	}
+	expected = []byte{
+		8, 0, 0, 0, // null bitmap
+		0, 0, 0, 0, 0, 0, 0x04, 0xd3, // 1235
+		0x40, 0x08, 0, 0, 0, 0, 0, 0, // 3.0
+		1, 0, 0, 0,
+	}
+	if !bytes.Equal(values, expected) {
+		t.Errorf("Incorrect values:%v", values)
+	}
+
+	for _, tc := range []struct {
+		x     xSQLVAR
+		param driver.Value
+	}{
+		{xSQLVAR{sqltype: SQL_TYPE_SHORT}, int64(40000)},
+		{xSQLVAR{sqltype: SQL_TYPE_LONG}, int64(1) << 40},
+		{xSQLVAR{sqltype: SQL_TYPE_LONG, sqlscale: -4}, float64(300000)},
+		{xSQLVAR{sqltype: SQL_TYPE_INT64}, "9223372036854775808"},
+	} {
+		_, _, err = p.paramsToBlr(0, []xSQLVAR{tc.x}, []driver.Value{tc.param}, PROTOCOL_VERSION13)
+		if err == nil {
+			t.Errorf("Overflow is not detected:%v %v", tc.x.typename(), tc.param)
+		}
+	}
+
+	_, _, err = p.paramsToBlr(0, xsqlda, []driver.Value{int64(1)}, PROTOCOL_VERSION13)
+	if err == nil {
+		t.Errorf("Argument count mismatch is not detected")
+	}
+}
+
+func TestParsePlan(t *testing.T) {
+	text := "\nPLAN (RDB$RELATIONS NATURAL)"
+	buf := append([]byte{isc_info_sql_get_plan, byte(len(text)), 0}, []byte(text)...)
+	buf = append(buf, isc_info_end)
+	plan, truncated, err := parse_plan(buf, isc_info_sql_get_plan)
+	if err != nil || truncated || plan != "PLAN (RDB$RELATIONS NATURAL)" {
+		t.Errorf("parse_plan() = %q, %v, %v", plan, truncated, err)
+	}
+
+	_, truncated, err = parse_plan([]byte{isc_info_truncated}, isc_info_sql_get_plan)
+	if err != nil || !truncated {
+		t.Errorf("Truncated plan is not detected")
+	}
+	_, truncated, err = parse_plan(buf[:10], isc_info_sql_get_plan)
+	if err != nil || !truncated {
+		t.Errorf("Short plan is not detected")
+	}
+
+	plan, truncated, err = parse_plan([]byte{isc_info_end}, isc_info_sql_get_plan)
+	if err != nil || truncated || plan != "" {
+		t.Errorf("Empty plan: %q, %v, %v", plan, truncated, err)
+	}
+}
+
+func TestDeferPackets(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	conn, err := newWireChannel(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &wireProtocol{buf: make([]byte, 0, BUFFER_LEN), conn: conn}
+
+	received := make(chan []byte, 1)
+	go func() {
+		b := make([]byte, 16)
+		n, _ := server.Read(b)
+		received <- b[:n]
+		server.Write([]byte{0, 0, 0, 3})
+	}()
+
+	p.deferPackets()
+	p.packInt(1)
+	p.sendPackets()
+	p.packInt(2)
+	p.sendPackets()
+	b, err := p.recvPackets(4) // sends the deferred requests
+	if err != nil || bytes_to_bint32(b) != 3 {
+		t.Fatalf("Incorrect response: %v %v", b, err)
+	}
+	if req := <-received; !bytes.Equal(req, []byte{0, 0, 0, 1, 0, 0, 0, 2}) {
+		t.Fatalf("Requests are not sent together: %v", req)
+	}
+	if p.deferFlush {
+		t.Fatalf("deferFlush is not reset")
+	}
+}